@@ -0,0 +1,117 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"example.com/playground/pkg/auth"
+
+	"github.com/urfave/cli/v2"
+)
+
+var authFlags = []cli.Flag{
+	&cli.BoolFlag{EnvVars: []string{"AUTH_DISABLED"}, Name: "auth-disabled", Usage: "serve the offers endpoints without authentication"},
+	&cli.StringSliceFlag{EnvVars: []string{"API_KEYS"}, Name: "api-keys", Usage: "static API keys as subject:role:key"},
+	&cli.StringFlag{EnvVars: []string{"API_KEYS_FILE"}, Name: "api-keys-file", Usage: "file with one subject:role:key API key per line"},
+	&cli.StringFlag{EnvVars: []string{"JWT_SECRET"}, Name: "jwt-secret", Usage: "secret verifying HS256 tokens"},
+	&cli.StringFlag{EnvVars: []string{"JWT_SECRET_FILE"}, Name: "jwt-secret-file", Usage: "file holding the secret verifying HS256 tokens"},
+	&cli.StringFlag{EnvVars: []string{"JWT_PUBLIC_KEY"}, Name: "jwt-public-key", Usage: "PEM encoded RSA public key verifying RS256 tokens"},
+	&cli.StringFlag{EnvVars: []string{"JWT_PUBLIC_KEY_FILE"}, Name: "jwt-public-key-file", Usage: "file holding the RSA public key verifying RS256 tokens"},
+	&cli.StringFlag{EnvVars: []string{"JWT_ISSUER"}, Name: "jwt-issuer", Usage: "required iss claim"},
+	&cli.StringFlag{EnvVars: []string{"JWT_AUDIENCE"}, Name: "jwt-audience", Usage: "required aud claim"},
+}
+
+// setupAuth builds the authenticator from the auth flags. It returns nil
+// when authentication is explicitly disabled and an error when it isn't
+// but nothing is configured, so a misconfigured server never runs open.
+func setupAuth(c *cli.Context) (auth.Authenticator, error) {
+	if c.Bool("auth-disabled") {
+		return nil, nil
+	}
+
+	var chain auth.Chain
+
+	entries := c.StringSlice("api-keys")
+	if path := c.String("api-keys-file"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		fromFile, err := auth.ReadAPIKeys(f)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, fromFile...)
+	}
+
+	if len(entries) > 0 {
+		keys, err := auth.ParseAPIKeys(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, keys)
+	}
+
+	secret, err := flagOrFile(c, "jwt-secret")
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := flagOrFile(c, "jwt-public-key")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secret) > 0 || len(publicKey) > 0 {
+		cfg := auth.JWTConfig{
+			HMACSecret: secret,
+			Issuer:     c.String("jwt-issuer"),
+			Audience:   c.String("jwt-audience"),
+		}
+
+		if len(publicKey) > 0 {
+			cfg.RSAPublicKey, err = auth.ParseRSAPublicKey(publicKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		j, err := auth.NewJWT(cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, j)
+	}
+
+	if len(chain) == 0 {
+		return nil, errors.New("no API keys or JWT keys configured, pass --auth-disabled to run without authentication")
+	}
+
+	return chain, nil
+}
+
+// flagOrFile returns the value of the name flag, or the contents of the
+// file named by the name-file flag without surrounding whitespace, such as
+// the trailing newline most secret files end with.
+func flagOrFile(c *cli.Context, name string) ([]byte, error) {
+	if v := c.String(name); v != "" {
+		return []byte(v), nil
+	}
+
+	if path := c.String(name + "-file"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return bytes.TrimSpace(data), nil
+	}
+
+	return nil, nil
+}
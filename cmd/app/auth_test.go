@@ -0,0 +1,50 @@
+package app
+
+import (
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/auth"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func authContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, f := range authFlags {
+		require.Nil(t, f.Apply(set))
+	}
+
+	require.Nil(t, set.Parse(args))
+
+	return cli.NewContext(cli.NewApp(), set, nil)
+}
+
+func TestSetupAuthSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt-secret")
+	require.Nil(t, os.WriteFile(path, []byte("secret\n"), 0o600))
+
+	a, err := setupAuth(authContext(t, "--jwt-secret-file", path))
+	require.Nil(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "alice",
+		"role": "recruiter",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("secret"))
+	require.Nil(t, err)
+
+	req := httptest.NewRequest("GET", "/offers", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	p, err := a.Authenticate(req)
+	assert.Nil(t, err)
+	assert.Equal(t, &auth.Principal{Subject: "alice", Role: auth.RoleRecruiter}, p)
+}
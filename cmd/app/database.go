@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+
+	"example.com/playground/pkg/storage"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageSQLite   = "sqlite"
+	storageMemory   = "memory"
+)
+
+var databaseFlags = []cli.Flag{
+	&cli.StringFlag{EnvVars: []string{"STORAGE"}, Name: "storage", Value: storagePostgres, Usage: "where offers are kept: postgres or sqlite, the server also takes memory"},
+	&cli.StringFlag{EnvVars: []string{"SQLITE_PATH"}, Name: "sqlite-path", Value: "offers.db", Usage: "SQLite database file, created when missing"},
+	&cli.StringFlag{EnvVars: []string{"POSTGRES_HOST"}, Name: "postgres-host", Value: "localhost"},
+	&cli.StringFlag{EnvVars: []string{"POSTGRES_PORT"}, Name: "postgres-port", Value: "5432"},
+	&cli.StringFlag{EnvVars: []string{"POSTGRES_DB"}, Name: "postgres-db", Value: "offers_db"},
+	&cli.StringFlag{EnvVars: []string{"POSTGRES_USER"}, Name: "postgres-user", Value: "postgres"},
+	&cli.StringFlag{EnvVars: []string{"POSTGRES_PASSWORD"}, Name: "postgres-password", Value: "your_password"},
+}
+
+func setupDatabase(c *cli.Context, lg *zap.SugaredLogger) (*gorm.DB, error) {
+	switch c.String("storage") {
+	case storagePostgres:
+	case storageSQLite:
+		return storage.SetupSQLite(&storage.SQLiteConfig{Path: c.String("sqlite-path")}, lg)
+	case storageMemory:
+		return nil, fmt.Errorf("%s storage has no database, %s needs %s or %s", storageMemory, c.Command.Name, storagePostgres, storageSQLite)
+	default:
+		return nil, fmt.Errorf("unknown storage %q, expected %s, %s or %s", c.String("storage"), storagePostgres, storageSQLite, storageMemory)
+	}
+
+	postgresConfig := &storage.PostgresConfig{
+		DatabaseName: c.String("postgres-db"),
+		Host:         c.String("postgres-host"),
+		Port:         c.String("postgres-port"),
+		User:         c.String("postgres-user"),
+		Password:     c.String("postgres-password"),
+	}
+
+	return storage.SetupDatabase(postgresConfig, lg)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"os/signal"
+	"syscall"
+
+	"example.com/playground/pkg/expiry"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var expire = cli.Command{
+	Name:  "expire",
+	Usage: "soft-delete offers past their expiration date",
+	Action: func(c *cli.Context) error {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			panic(err)
+		}
+
+		lg := logger.Sugar()
+
+		db, err := setupDatabase(c, lg)
+		if err != nil {
+			return err
+		}
+
+		sweeper := expiry.NewSweeper(
+			offers.NewService(storage.NewRepository(db, nil), nil),
+			c.Duration("interval"),
+			c.Int("batch-size"),
+			lg,
+		)
+
+		// SIGINT/SIGTERM cancel the sweep, rolling back the batch in
+		// flight, instead of killing the process in the middle of it.
+		ctx, stop := signal.NotifyContext(c.Context, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if c.Bool("once") {
+			_, err := sweeper.Sweep(ctx)
+
+			return err
+		}
+
+		sweeper.Run(ctx)
+
+		return nil
+	},
+	Flags: append([]cli.Flag{
+		&cli.DurationFlag{EnvVars: []string{"EXPIRY_INTERVAL"}, Name: "interval", Value: expiry.DefaultInterval},
+		&cli.IntFlag{EnvVars: []string{"EXPIRY_BATCH_SIZE"}, Name: "batch-size", Value: expiry.DefaultBatchSize},
+		&cli.BoolFlag{Name: "once", Usage: "sweep a single time and exit"},
+	}, databaseFlags...),
+}
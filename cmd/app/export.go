@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offerio"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var exportOffers = cli.Command{
+	Name:      "export",
+	Usage:     "export offers to a CSV or JSON Lines file",
+	ArgsUsage: "[FILE]",
+	Action: func(c *cli.Context) error {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			panic(err)
+		}
+
+		lg := logger.Sugar()
+
+		path := c.Args().First()
+		if path == "" {
+			path = "-"
+		}
+
+		format, err := exportFormat(c, path)
+		if err != nil {
+			return err
+		}
+
+		filter, err := exportFilter(c)
+		if err != nil {
+			return err
+		}
+
+		db, err := setupDatabase(c, lg)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = c.App.Writer
+		if path != "-" {
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			out = f
+		}
+
+		buf := bufio.NewWriter(out)
+
+		w, err := offerio.NewWriter(buf, format)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+
+		var exported int
+
+		if err := offers.NewService(storage.NewRepository(db, nil), nil).Export(c.Context, filter, func(o *api.JobOfferResponse) error {
+			exported++
+
+			return w.Write(o)
+		}); err != nil {
+			return err
+		}
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+
+		lg.Infow("export finished",
+			"exported", exported,
+			"duration", time.Since(start))
+
+		return nil
+	},
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{Name: "format", Usage: "csv or jsonl, by default taken from the file extension, jsonl on standard output"},
+		&cli.StringFlag{Name: "company", Usage: "only offers of this company"},
+		&cli.StringFlag{Name: "owner", Usage: "only offers owned by this subject"},
+		&cli.Float64Flag{Name: "min-salary"},
+		&cli.Float64Flag{Name: "max-salary"},
+		&cli.TimestampFlag{Name: "expires-after", Layout: time.RFC3339},
+		&cli.TimestampFlag{Name: "expires-before", Layout: time.RFC3339},
+		&cli.StringFlag{Name: "q", Usage: "full-text search over company and details"},
+		&cli.BoolFlag{Name: "include-expired", Usage: "export expired offers as well"},
+	}, databaseFlags...),
+}
+
+func exportFormat(c *cli.Context, path string) (offerio.Format, error) {
+	if f := c.String("format"); f != "" {
+		return offerio.ParseFormat(f)
+	}
+
+	if path == "-" {
+		return offerio.FormatJSONL, nil
+	}
+
+	return offerio.FormatFromPath(path)
+}
+
+// exportFilter builds the same filter as the query parameters of the
+// listing from the flags.
+func exportFilter(c *cli.Context) (*api.OffersFilter, error) {
+	filter := &api.OffersFilter{
+		Company:        c.String("company"),
+		Owner:          c.String("owner"),
+		ExpiresAfter:   c.Timestamp("expires-after"),
+		ExpiresBefore:  c.Timestamp("expires-before"),
+		Query:          c.String("q"),
+		IncludeExpired: c.Bool("include-expired"),
+	}
+
+	if c.IsSet("min-salary") {
+		v := c.Float64("min-salary")
+		filter.MinSalary = &v
+	}
+
+	if c.IsSet("max-salary") {
+		v := c.Float64("max-salary")
+		filter.MaxSalary = &v
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
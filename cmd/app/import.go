@@ -0,0 +1,97 @@
+package app
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/offerio"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var errImportFile = errors.New("expected exactly one file, or - for standard input")
+
+var importOffers = cli.Command{
+	Name:      "import",
+	Usage:     "import offers from a CSV or JSON Lines file",
+	ArgsUsage: "FILE",
+	Action: func(c *cli.Context) error {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			panic(err)
+		}
+
+		lg := logger.Sugar()
+
+		if c.NArg() != 1 {
+			return errImportFile
+		}
+
+		path := c.Args().First()
+
+		format, err := importFormat(c, path)
+		if err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		if path != "-" {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			in = f
+		}
+
+		r, err := offerio.NewReader(in, format)
+		if err != nil {
+			return err
+		}
+
+		// A dry run only validates, there is no need for a database.
+		var creator offers.BatchCreateOffers
+		if !c.Bool("dry-run") {
+			db, err := setupDatabase(c, lg)
+			if err != nil {
+				return err
+			}
+
+			creator = offers.NewService(storage.NewRepository(db, nil), nil)
+		}
+
+		ctx := c.Context
+		if owner := c.String("owner"); owner != "" {
+			ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: owner, Role: auth.RoleRecruiter})
+		}
+
+		_, err = offerio.NewImporter(creator, offerio.ImportOptions{
+			BatchSize:   c.Int("batch-size"),
+			DryRun:      c.Bool("dry-run"),
+			SkipInvalid: c.Bool("skip-invalid"),
+		}, lg).Import(ctx, r)
+
+		return err
+	},
+	Flags: append([]cli.Flag{
+		&cli.StringFlag{Name: "format", Usage: "csv or jsonl, by default taken from the file extension"},
+		&cli.IntFlag{Name: "batch-size", Value: offerio.DefaultBatchSize, Usage: "number of offers written per transaction"},
+		&cli.BoolFlag{Name: "dry-run", Usage: "validate the file without writing anything"},
+		&cli.BoolFlag{Name: "skip-invalid", Usage: "import the valid rows even if some are invalid or rejected"},
+		&cli.StringFlag{Name: "owner", Usage: "subject recorded as the owner of the imported offers"},
+	}, databaseFlags...),
+}
+
+func importFormat(c *cli.Context, path string) (offerio.Format, error) {
+	if f := c.String("format"); f != "" {
+		return offerio.ParseFormat(f)
+	}
+
+	return offerio.FormatFromPath(path)
+}
@@ -7,6 +7,11 @@ func New() *cli.App {
 	app.Name = "GOLANG playground"
 	app.Commands = []*cli.Command{
 		&server,
+		&expire,
+		&purge,
+		&migrate,
+		&importOffers,
+		&exportOffers,
 	}
 
 	return app
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"example.com/playground/pkg/migrations"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var migrate = cli.Command{
+	Name:  "migrate",
+	Usage: "manage the database schema",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "up",
+			Usage: "apply all pending migrations",
+			Action: withMigrator(func(c *cli.Context, m *migrations.Migrator, lg *zap.SugaredLogger) error {
+				applied, err := m.Up(c.Context)
+				for _, migration := range applied {
+					lg.Infow("migration applied", "version", migration.Version, "name", migration.Name)
+				}
+
+				return err
+			}),
+			Flags: databaseFlags,
+		},
+		{
+			Name:  "down",
+			Usage: "revert the most recently applied migrations",
+			Action: withMigrator(func(c *cli.Context, m *migrations.Migrator, lg *zap.SugaredLogger) error {
+				reverted, err := m.Down(c.Context, c.Int("steps"))
+				for _, migration := range reverted {
+					lg.Infow("migration reverted", "version", migration.Version, "name", migration.Name)
+				}
+
+				return err
+			}),
+			Flags: append([]cli.Flag{
+				&cli.IntFlag{Name: "steps", Value: 1, Usage: "number of migrations to revert"},
+			}, databaseFlags...),
+		},
+		{
+			Name:  "status",
+			Usage: "list migrations and whether they are applied",
+			Action: withMigrator(func(c *cli.Context, m *migrations.Migrator, _ *zap.SugaredLogger) error {
+				statuses, err := m.Status(c.Context)
+				if err != nil {
+					return err
+				}
+
+				w := tabwriter.NewWriter(c.App.Writer, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+				for _, s := range statuses {
+					appliedAt := "pending"
+					if s.AppliedAt != nil {
+						appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+					}
+
+					fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+				}
+
+				return w.Flush()
+			}),
+			Flags: databaseFlags,
+		},
+		{
+			Name:  "version",
+			Usage: "print the current schema version",
+			Action: withMigrator(func(c *cli.Context, m *migrations.Migrator, _ *zap.SugaredLogger) error {
+				version, err := m.Version(c.Context)
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintln(c.App.Writer, version)
+
+				return nil
+			}),
+			Flags: databaseFlags,
+		},
+	},
+}
+
+func withMigrator(action func(*cli.Context, *migrations.Migrator, *zap.SugaredLogger) error) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			panic(err)
+		}
+
+		lg := logger.Sugar()
+
+		db, err := setupDatabase(c, lg)
+		if err != nil {
+			return err
+		}
+
+		m, err := migrations.NewMigrator(db)
+		if err != nil {
+			return err
+		}
+
+		return action(c, m, lg)
+	}
+}
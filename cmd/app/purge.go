@@ -0,0 +1,63 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
+)
+
+var errPurgeBatchSize = errors.New("batch size must be positive")
+
+var purge = cli.Command{
+	Name:  "purge",
+	Usage: "permanently delete offers that have been in the trash for longer than the retention",
+	Action: func(c *cli.Context) error {
+		if c.Int("batch-size") <= 0 {
+			return errPurgeBatchSize
+		}
+
+		logger, err := zap.NewProduction()
+		if err != nil {
+			panic(err)
+		}
+
+		lg := logger.Sugar()
+
+		db, err := setupDatabase(c, lg)
+		if err != nil {
+			return err
+		}
+
+		service := offers.NewService(storage.NewRepository(db, nil), nil)
+		before := time.Now().Add(-c.Duration("retention"))
+		batchSize := c.Int("batch-size")
+
+		var total int64
+
+		for {
+			purged, err := service.PurgeDeleted(c.Context, before, batchSize)
+			if err != nil {
+				return err
+			}
+
+			total += purged
+
+			if purged < int64(batchSize) {
+				break
+			}
+		}
+
+		lg.Infow("offers purged", "count", total, "deletedBefore", before)
+
+		return nil
+	},
+	Flags: append([]cli.Flag{
+		&cli.DurationFlag{EnvVars: []string{"PURGE_RETENTION"}, Name: "retention", Value: 30 * 24 * time.Hour, Usage: "how long deleted offers are kept in the trash"},
+		&cli.IntFlag{EnvVars: []string{"PURGE_BATCH_SIZE"}, Name: "batch-size", Value: 1000},
+	}, databaseFlags...),
+}
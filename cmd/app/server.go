@@ -1,13 +1,29 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"example.com/playground/pkg/expiry"
+	"example.com/playground/pkg/migrations"
+	"example.com/playground/pkg/offers"
 	"example.com/playground/pkg/rest"
 	"example.com/playground/pkg/storage"
+	"example.com/playground/pkg/tracing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 var server = cli.Command{
@@ -20,35 +36,261 @@ var server = cli.Command{
 
 		lg := logger.Sugar()
 
-		postgresConfig := &storage.PostgresConfig{
-			DatabaseName: c.String("postgres-db"),
-			Host:         c.String("postgres-host"),
-			Port:         c.String("postgres-port"),
-			User:         c.String("postgres-user"),
-			Password:     c.String("postgres-password"),
+		cursorKey := []byte(c.String("cursor-secret"))
+		if len(cursorKey) == 0 {
+			lg.Warn("no cursor secret configured, pagination cursors won't survive restarts or work across replicas")
+
+			cursorKey = make([]byte, 32)
+			if _, err := rand.Read(cursorKey); err != nil {
+				return err
+			}
 		}
 
-		db, err := storage.SetupDatabase(postgresConfig, lg)
+		repo, readinessChecks, db, err := setupStorage(c, cursorKey, lg)
 		if err != nil {
 			return err
 		}
 
+		service := offers.NewService(repo, &offers.Config{
+			Listeners: []offers.Listener{logEvents(lg)},
+		})
+
+		ctx, stop := signal.NotifyContext(c.Context, syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		shutdownTracing, err := tracing.Setup(ctx, &tracing.Config{
+			Exporter:     c.String("tracing-exporter"),
+			OTLPEndpoint: c.String("otlp-endpoint"),
+			OTLPInsecure: c.Bool("otlp-insecure"),
+			SampleRatio:  c.Float64("tracing-sample-ratio"),
+			ServiceName:  "offers",
+		})
+		if err != nil {
+			return err
+		}
+
+		authenticator, err := setupAuth(c)
+		if err != nil {
+			return err
+		}
+
+		if authenticator == nil {
+			lg.Warn("authentication is disabled, anyone can create, update and delete offers")
+		}
+
+		var registry *prometheus.Registry
+		if c.Bool("metrics") {
+			registry = prometheus.NewRegistry()
+			registry.MustRegister(
+				collectors.NewGoCollector(),
+				collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+			)
+
+			storageCollectors, err := storage.MetricsCollectors(db)
+			if err != nil {
+				return err
+			}
+
+			registry.MustRegister(storageCollectors...)
+		}
+
 		r := rest.SetupRouteHandlers(&rest.RouteHandlers{
-			CreateOffer:  storage.NewCreateOfferService(db),
-			UpdateOffer:  storage.NewUpdateOfferService(db),
-			GetOffer:     storage.NewGetOfferService(db),
-			DeleteOffer:  storage.NewDeleteOfferService(db),
-			GetAllOffers: storage.NewGetAllOffersService(db),
+			CreateOffer:  service,
+			UpdateOffer:  service,
+			PatchOffer:   service,
+			GetOffer:     service,
+			DeleteOffer:  service,
+			ExportOffers: service,
+			GetAllOffers: service,
+
+			GetTrash:     service,
+			RestoreOffer: service,
+			PurgeOffer:   service,
+
+			BatchCreateOffers: service,
+			BatchPatchOffers:  service,
+			BatchDeleteOffers: service,
+
+			ReadinessChecks:  readinessChecks,
+			ReadinessTimeout: c.Duration("health-check-timeout"),
+			Metrics:          registry,
+			MetricsPath:      c.String("metrics-path"),
+			Logging: &rest.LoggingConfig{
+				RedactedHeaders: c.StringSlice("log-redact-headers"),
+				RedactedFields:  c.StringSlice("log-redact-fields"),
+				MaxBodyBytes:    c.Int("log-max-body-bytes"),
+			},
+			Auth:           authenticator,
+			RequireIfMatch: c.Bool("require-if-match"),
 		}, lg)
 
-		return r.Run(fmt.Sprintf(":%s", c.String("server-port")))
+		srv := &http.Server{
+			Addr:              fmt.Sprintf(":%s", c.String("server-port")),
+			Handler:           r,
+			ReadTimeout:       c.Duration("read-timeout"),
+			ReadHeaderTimeout: c.Duration("read-header-timeout"),
+			WriteTimeout:      c.Duration("write-timeout"),
+			IdleTimeout:       c.Duration("idle-timeout"),
+		}
+
+		ln, err := net.Listen("tcp", srv.Addr)
+		if err != nil {
+			return err
+		}
+
+		lg.Infow("server started", "addr", ln.Addr().String())
+
+		// The sweeper starts last so that no early return leaves it behind.
+		sweeperDone := make(chan struct{})
+		if c.Bool("expiry-worker") {
+			go func() {
+				defer close(sweeperDone)
+
+				expiry.NewSweeper(
+					service,
+					c.Duration("expiry-interval"),
+					c.Int("expiry-batch-size"),
+					lg,
+				).Run(ctx)
+			}()
+		} else {
+			close(sweeperDone)
+		}
+
+		serveErr := serve(ctx, srv, ln, c.Duration("shutdown-grace-period"), lg)
+
+		// serve also returns when the server fails, so the sweeper has to be
+		// told to stop rather than waiting for a signal.
+		stop()
+		<-sweeperDone
+
+		flushCtx, cancel := context.WithTimeout(context.Background(), c.Duration("shutdown-grace-period"))
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			lg.Errorw("flushing traces failed", "error", err)
+		}
+
+		if db != nil {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+
+			if err := sqlDB.Close(); err != nil {
+				lg.Errorw("closing database connections failed", "error", err)
+			}
+		}
+
+		return serveErr
 	},
-	Flags: []cli.Flag{
+	Flags: append([]cli.Flag{
 		&cli.StringFlag{EnvVars: []string{"SERVER_PORT"}, Name: "server-port", Value: "3456"},
-		&cli.StringFlag{EnvVars: []string{"POSTGRES_HOST"}, Name: "postgres-host", Value: "localhost"},
-		&cli.StringFlag{EnvVars: []string{"POSTGRES_PORT"}, Name: "postgres-port", Value: "5432"},
-		&cli.StringFlag{EnvVars: []string{"POSTGRES_DB"}, Name: "postgres-db", Value: "offers_db"},
-		&cli.StringFlag{EnvVars: []string{"POSTGRES_USER"}, Name: "postgres-user", Value: "postgres"},
-		&cli.StringFlag{EnvVars: []string{"POSTGRES_PASSWORD"}, Name: "postgres-password", Value: "your_password"},
-	},
+		&cli.DurationFlag{EnvVars: []string{"SHUTDOWN_GRACE_PERIOD"}, Name: "shutdown-grace-period", Value: 15 * time.Second, Usage: "how long to wait for in-flight requests on shutdown"},
+		&cli.DurationFlag{EnvVars: []string{"READ_TIMEOUT"}, Name: "read-timeout", Value: 15 * time.Second},
+		&cli.DurationFlag{EnvVars: []string{"READ_HEADER_TIMEOUT"}, Name: "read-header-timeout", Value: 5 * time.Second},
+		&cli.DurationFlag{EnvVars: []string{"WRITE_TIMEOUT"}, Name: "write-timeout", Value: 30 * time.Second},
+		&cli.DurationFlag{EnvVars: []string{"IDLE_TIMEOUT"}, Name: "idle-timeout", Value: 60 * time.Second},
+		&cli.DurationFlag{EnvVars: []string{"HEALTH_CHECK_TIMEOUT"}, Name: "health-check-timeout", Value: 2 * time.Second},
+		&cli.BoolFlag{EnvVars: []string{"METRICS"}, Name: "metrics", Value: true, Usage: "expose Prometheus metrics"},
+		&cli.StringFlag{EnvVars: []string{"METRICS_PATH"}, Name: "metrics-path", Value: "/metrics"},
+		&cli.BoolFlag{EnvVars: []string{"REQUIRE_MIGRATIONS"}, Name: "require-migrations", Usage: "refuse to start while migrations are pending"},
+		&cli.BoolFlag{EnvVars: []string{"REQUIRE_IF_MATCH"}, Name: "require-if-match", Usage: "reject offer updates and deletes without an If-Match header"},
+		&cli.StringFlag{EnvVars: []string{"CURSOR_SECRET"}, Name: "cursor-secret", Usage: "key used to sign pagination cursors"},
+		&cli.BoolFlag{EnvVars: []string{"EXPIRY_WORKER"}, Name: "expiry-worker", Usage: "sweep expired offers in-process"},
+		&cli.DurationFlag{EnvVars: []string{"EXPIRY_INTERVAL"}, Name: "expiry-interval", Value: expiry.DefaultInterval},
+		&cli.IntFlag{EnvVars: []string{"EXPIRY_BATCH_SIZE"}, Name: "expiry-batch-size", Value: expiry.DefaultBatchSize},
+		&cli.IntFlag{EnvVars: []string{"LOG_MAX_BODY_BYTES"}, Name: "log-max-body-bytes", Value: rest.DefaultMaxBodyBytes, Usage: "how much of a request body to log, -1 to log none"},
+		&cli.StringSliceFlag{EnvVars: []string{"LOG_REDACT_HEADERS"}, Name: "log-redact-headers", Usage: "request headers masked in logs on top of " + strings.Join(rest.DefaultRedactedHeaders, ", ")},
+		&cli.StringSliceFlag{EnvVars: []string{"LOG_REDACT_FIELDS"}, Name: "log-redact-fields", Usage: "JSON body fields and query parameters masked in logs on top of " + strings.Join(rest.DefaultRedactedFields, ", ")},
+		&cli.StringFlag{EnvVars: []string{"TRACING_EXPORTER"}, Name: "tracing-exporter", Value: tracing.ExporterNone, Usage: "where to send traces: none, stdout or otlp"},
+		&cli.StringFlag{EnvVars: []string{"OTLP_ENDPOINT"}, Name: "otlp-endpoint", Value: "localhost:4318", Usage: "host:port of the OTLP/HTTP collector"},
+		&cli.BoolFlag{EnvVars: []string{"OTLP_INSECURE"}, Name: "otlp-insecure", Usage: "send traces to the collector over plain HTTP"},
+		&cli.Float64Flag{EnvVars: []string{"TRACING_SAMPLE_RATIO"}, Name: "tracing-sample-ratio", Value: 1, Usage: "fraction of new traces to sample"},
+	}, append(authFlags, databaseFlags...)...),
+}
+
+// setupStorage picks the backend offers are kept in. The database is nil
+// for the in-memory backend.
+func setupStorage(c *cli.Context, cursorKey []byte, lg *zap.SugaredLogger) (storage.OfferRepository, map[string]storage.HealthCheck, *gorm.DB, error) {
+	if c.String("storage") == storageMemory {
+		lg.Warn("offers are kept in memory, they are lost when the server stops")
+
+		return storage.NewMemoryRepository(cursorKey), map[string]storage.HealthCheck{}, nil, nil
+	}
+
+	db, err := setupDatabase(c, lg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	m, err := migrations.NewMigrator(db)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if c.Bool("require-migrations") {
+		pending, err := m.Pending(c.Context)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if len(pending) > 0 {
+			return nil, nil, nil, fmt.Errorf("%d database migrations are pending, run `migrate up` first", len(pending))
+		}
+	}
+
+	return storage.NewRepository(db, cursorKey), map[string]storage.HealthCheck{
+		"database":   storage.NewHealthCheckService(db),
+		"migrations": m,
+	}, db, nil
+}
+
+// logEvents logs every change made to offers.
+func logEvents(lg *zap.SugaredLogger) offers.Listener {
+	return func(ctx context.Context, e offers.Event) {
+		if e.Type == offers.Expired {
+			lg.Infow("offers expired", "count", e.Count)
+
+			return
+		}
+
+		if e.Type == offers.Purged && e.OfferID == "" {
+			lg.Infow("offers purged", "count", e.Count)
+
+			return
+		}
+
+		lg.Infow("offer "+string(e.Type), "offerID", e.OfferID)
+	}
+}
+
+// serve runs srv on ln until ctx is cancelled and then drains in-flight
+// requests for at most grace before giving up on them.
+func serve(ctx context.Context, srv *http.Server, ln net.Listener, grace time.Duration, lg *zap.SugaredLogger) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	lg.Infow("shutting down server", "gracePeriod", grace)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
 }
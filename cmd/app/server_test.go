@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	started := make(chan struct{})
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, ln, time.Second, zaptest.NewLogger(t).Sugar())
+	}()
+
+	responses := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			responses <- 0
+
+			return
+		}
+		defer resp.Body.Close()
+
+		responses <- resp.StatusCode
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, http.StatusOK, <-responses)
+	assert.Nil(t, <-served)
+}
+
+func TestServeGivesUpAfterGracePeriod(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+		}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, srv, ln, 50*time.Millisecond, zaptest.NewLogger(t).Sugar())
+	}()
+
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, <-served)
+}
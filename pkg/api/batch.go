@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/go-ozzo/ozzo-validation/is"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Batch modes. An all-or-nothing batch is rolled back as soon as one item
+// fails while a best-effort batch commits the items that succeeded.
+const (
+	BatchAllOrNothing = "all_or_nothing"
+	BatchBestEffort   = "best_effort"
+)
+
+// MaxBatchSize caps the number of items of a single batch request.
+const MaxBatchSize = 1000
+
+type BatchCreateRequest struct {
+	Mode   string            `json:"mode"`
+	Offers []JobOfferRequest `json:"offers"`
+}
+
+// Validate checks the batch as a whole. The offers are validated one by
+// one by the caller so that the errors can be attributed to them.
+func (req BatchCreateRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Mode, validation.In(BatchAllOrNothing, BatchBestEffort)),
+		validation.Field(&req.Offers, validation.Required, validation.Length(1, MaxBatchSize), validation.Skip),
+	)
+}
+
+func (req BatchCreateRequest) BestEffort() bool { return req.Mode == BatchBestEffort }
+
+// BatchPatchRequest applies a merge patch to each of many offers.
+type BatchPatchRequest struct {
+	Mode   string           `json:"mode"`
+	Offers []BatchPatchItem `json:"offers"`
+}
+
+func (req BatchPatchRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Mode, validation.In(BatchAllOrNothing, BatchBestEffort)),
+		validation.Field(&req.Offers, validation.Required, validation.Length(1, MaxBatchSize), validation.Skip),
+	)
+}
+
+func (req BatchPatchRequest) BestEffort() bool { return req.Mode == BatchBestEffort }
+
+// BatchPatchItem is a merge patch of the offer with the given id. A
+// non-zero version makes it conditional, as If-Match does for a single
+// offer.
+type BatchPatchItem struct {
+	ID      string               `json:"id"`
+	Version int64                `json:"version"`
+	Patch   PatchJobOfferRequest `json:"patch"`
+}
+
+// Validate reports the errors of the patch as patch.<field>.
+func (item BatchPatchItem) Validate() error {
+	errs := validation.Errors{}
+
+	if err := validation.Validate(item.ID, validation.Required, is.UUID); err != nil {
+		errs["id"] = err
+	}
+
+	if err := validation.Validate(item.Version, validation.Min(int64(0))); err != nil {
+		errs["version"] = err
+	}
+
+	if err := item.Patch.Validate(); err != nil {
+		var fields validation.Errors
+		if !errors.As(err, &fields) {
+			return err
+		}
+
+		for field, e := range fields {
+			errs["patch."+field] = e
+		}
+	}
+
+	return errs.Filter()
+}
+
+type BatchDeleteRequest struct {
+	Mode string            `json:"mode"`
+	IDs  []BatchDeleteItem `json:"ids"`
+}
+
+func (req BatchDeleteRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Mode, validation.In(BatchAllOrNothing, BatchBestEffort)),
+		validation.Field(&req.IDs, validation.Required, validation.Length(1, MaxBatchSize), validation.Skip),
+	)
+}
+
+func (req BatchDeleteRequest) BestEffort() bool { return req.Mode == BatchBestEffort }
+
+// BatchDeleteItem is the id of an offer to delete, given either on its own
+// or as an object with the version to make the delete conditional on.
+type BatchDeleteItem struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+}
+
+func (item *BatchDeleteItem) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*item = BatchDeleteItem{}
+
+		return json.Unmarshal(data, &item.ID)
+	}
+
+	type plain BatchDeleteItem
+
+	return json.Unmarshal(data, (*plain)(item))
+}
+
+// Validate reports an invalid id as the error of the whole item, as it
+// would be for a bare id, and an invalid version as version.
+func (item BatchDeleteItem) Validate() error {
+	if err := validation.Validate(item.ID, validation.Required, is.UUID); err != nil {
+		return err
+	}
+
+	return validation.Errors{
+		"version": validation.Validate(item.Version, validation.Min(int64(0))),
+	}.Filter()
+}
+
+// BatchItemResult reports the outcome of the item at the same position of
+// the request, with the status code it would have had on its own.
+type BatchItemResult struct {
+	Status int               `json:"status"`
+	ID     string            `json:"uuid,omitempty"`
+	Offer  *JobOfferResponse `json:"offer,omitempty"`
+	Error  *ErrorResponse    `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
@@ -0,0 +1,20 @@
+package api
+
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeInternal             = "internal_error"
+)
+
+type ErrorResponse struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
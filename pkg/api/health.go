@@ -0,0 +1,34 @@
+package api
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+
+	CheckStatusUp   = "up"
+	CheckStatusDown = "down"
+)
+
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+type CheckResult struct {
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+type PoolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+}
+
+type MigrationStats struct {
+	Version int64 `json:"version"`
+	Pending int   `json:"pending"`
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+)
+
+var jsonNull = []byte("null")
+
+// OptionalString tells apart a JSON field that was left out (Set is false),
+// one that was null (Set but not Valid) and one that carried a value.
+type OptionalString struct {
+	Set    bool
+	Valid  bool
+	String string
+}
+
+func (o *OptionalString) UnmarshalJSON(b []byte) error {
+	o.Set = true
+
+	if bytes.Equal(b, jsonNull) {
+		o.Valid = false
+
+		return nil
+	}
+
+	o.Valid = true
+
+	return json.Unmarshal(b, &o.String)
+}
+
+// Value lets validation rules see the string itself, or nil when it is
+// missing or null.
+func (o OptionalString) Value() (driver.Value, error) {
+	if !o.Valid {
+		return nil, nil
+	}
+
+	return o.String, nil
+}
+
+// OptionalFloat is the float64 counterpart of OptionalString.
+type OptionalFloat struct {
+	Set     bool
+	Valid   bool
+	Float64 float64
+}
+
+func (o *OptionalFloat) UnmarshalJSON(b []byte) error {
+	o.Set = true
+
+	if bytes.Equal(b, jsonNull) {
+		o.Valid = false
+
+		return nil
+	}
+
+	o.Valid = true
+
+	return json.Unmarshal(b, &o.Float64)
+}
+
+func (o OptionalFloat) Value() (driver.Value, error) {
+	if !o.Valid {
+		return nil, nil
+	}
+
+	return o.Float64, nil
+}
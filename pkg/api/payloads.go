@@ -1,6 +1,9 @@
 package api
 
 import (
+	"errors"
+	"time"
+
 	"github.com/go-ozzo/ozzo-validation/is"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
@@ -23,11 +26,76 @@ func (req JobOfferRequest) Validate() error {
 		validation.Field(&req.Salary, validation.Required),
 		validation.Field(&req.LinkToOffer, validation.Required, is.URL),
 		validation.Field(&req.ContactPhone, validation.Required, is.E164),
+		validation.Field(&req.ExpirationDate, validation.By(isExpirationDate), validation.By(isFuture)),
 	)
 }
 
+// ParseExpirationDate accepts either an RFC 3339 timestamp or an ISO 8601
+// calendar date, the latter meaning midnight UTC of that day.
+func ParseExpirationDate(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+	}
+
+	if err != nil {
+		return time.Time{}, errInvalidExpirationDate
+	}
+
+	return t.UTC(), nil
+}
+
+var (
+	errInvalidExpirationDate = errors.New("must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	errPastExpirationDate    = errors.New("must be in the future")
+)
+
+func isExpirationDate(value interface{}) error {
+	v, _ := validation.Indirect(value)
+	s, _ := v.(string)
+	if s == "" {
+		return nil
+	}
+
+	_, err := ParseExpirationDate(s)
+
+	return err
+}
+
+func isFuture(value interface{}) error {
+	v, _ := validation.Indirect(value)
+	s, _ := v.(string)
+
+	t, err := ParseExpirationDate(s)
+	if err != nil {
+		return nil
+	}
+
+	if !t.After(time.Now()) {
+		return errPastExpirationDate
+	}
+
+	return nil
+}
+
 type JobOfferResponse struct {
-	ID             string  `json:"uuid"`
+	ID             string     `json:"uuid"`
+	Company        string     `json:"company"`
+	Email          string     `json:"email"`
+	ExpirationDate *time.Time `json:"expiration_date"`
+	LinkToOffer    string     `json:"link"`
+	Details        string     `json:"details"`
+	Salary         float64    `json:"salary"`
+	ContactPhone   string     `json:"phone"`
+	Version        int64      `json:"version"`
+
+	// DeletedAt is only set for offers in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// UpdateJobOfferRequest replaces every mutable field of an offer. Optional
+// fields left out are cleared.
+type UpdateJobOfferRequest struct {
 	Company        string  `json:"company"`
 	Email          string  `json:"email"`
 	ExpirationDate string  `json:"expiration_date"`
@@ -37,23 +105,101 @@ type JobOfferResponse struct {
 	ContactPhone   string  `json:"phone"`
 }
 
-type UpdateJobOfferRequest struct {
-	Salary       float64 `json:"salary"`
-	Email        string  `json:"email"`
-	ContactPhone string  `json:"phone"`
-	LinkToOffer  string  `json:"link"`
-}
-
 func (req UpdateJobOfferRequest) Validate() error {
 	return validation.ValidateStruct(&req,
+		validation.Field(&req.Company, validation.Required),
 		validation.Field(&req.Email, validation.Required, is.Email),
 		validation.Field(&req.Salary, validation.Required),
 		validation.Field(&req.LinkToOffer, validation.Required, is.URL),
 		validation.Field(&req.ContactPhone, validation.Required, is.E164),
+		validation.Field(&req.ExpirationDate, validation.By(isExpirationDate), validation.By(isFuture)),
+	)
+}
+
+// PatchJobOfferRequest is an RFC 7396 merge patch of an offer: fields that
+// are left out stay as they are and fields set to null are cleared, which
+// only the optional details and expiration_date allow.
+type PatchJobOfferRequest struct {
+	Company        OptionalString `json:"company"`
+	Email          OptionalString `json:"email"`
+	ExpirationDate OptionalString `json:"expiration_date"`
+	LinkToOffer    OptionalString `json:"link"`
+	Details        OptionalString `json:"details"`
+	Salary         OptionalFloat  `json:"salary"`
+	ContactPhone   OptionalString `json:"phone"`
+}
+
+func (req PatchJobOfferRequest) Validate() error {
+	return validation.ValidateStruct(&req,
+		validation.Field(&req.Company, validation.When(req.Company.Set, validation.Required)),
+		validation.Field(&req.Email, validation.When(req.Email.Set, validation.Required, is.Email)),
+		validation.Field(&req.Salary, validation.When(req.Salary.Set, validation.Required)),
+		validation.Field(&req.LinkToOffer, validation.When(req.LinkToOffer.Set, validation.Required, is.URL)),
+		validation.Field(&req.ContactPhone, validation.When(req.ContactPhone.Set, validation.Required, is.E164)),
+		// Clearing the expiration date takes null, not an empty string.
+		validation.Field(&req.ExpirationDate, validation.When(req.ExpirationDate.Valid, validation.Required), validation.By(isExpirationDate), validation.By(isFuture)),
+	)
+}
+
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+func (k SortKey) String() string {
+	if k.Descending {
+		return "-" + k.Field
+	}
+
+	return k.Field
+}
+
+type PageRequest struct {
+	Size   int       `json:"size"`
+	Offset int       `json:"offset"`
+	Cursor string    `json:"cursor"`
+	Sort   []SortKey `json:"sort"`
+}
+
+func (p PageRequest) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Size, validation.Required, validation.Min(1)),
+		validation.Field(&p.Offset, validation.Min(0)),
+		validation.Field(&p.Sort, validation.Required),
 	)
 }
 
 type JobOffersPaginationResponse struct {
-	TotalCount int64              `json:"total_count"`
+	TotalCount *int64             `json:"total_count,omitempty"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	PrevCursor string             `json:"prev_cursor,omitempty"`
 	Data       []JobOfferResponse `json:"data"`
 }
+
+type OffersFilter struct {
+	Company       string     `json:"company,omitempty"`
+	Owner         string     `json:"owner,omitempty"`
+	MinSalary     *float64   `json:"min_salary,omitempty"`
+	MaxSalary     *float64   `json:"max_salary,omitempty"`
+	ExpiresAfter  *time.Time `json:"expires_after,omitempty"`
+	ExpiresBefore *time.Time `json:"expires_before,omitempty"`
+	Query         string     `json:"q,omitempty"`
+
+	IncludeExpired bool `json:"include_expired,omitempty"`
+}
+
+func (f OffersFilter) Validate() error {
+	var maxSalaryRules, expiresBeforeRules []validation.Rule
+	if f.MinSalary != nil {
+		maxSalaryRules = append(maxSalaryRules, validation.Min(*f.MinSalary))
+	}
+	if f.ExpiresAfter != nil {
+		expiresBeforeRules = append(expiresBeforeRules, validation.Min(*f.ExpiresAfter))
+	}
+
+	return validation.ValidateStruct(&f,
+		validation.Field(&f.MinSalary, validation.Min(0.0)),
+		validation.Field(&f.MaxSalary, maxSalaryRules...),
+		validation.Field(&f.ExpiresBefore, expiresBeforeRules...),
+	)
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const APIKeyHeader = "X-Api-Key"
+
+type apiKey struct {
+	hash      [sha256.Size]byte
+	principal Principal
+}
+
+// APIKeys authenticates requests by a static key sent in the X-Api-Key
+// header. Only hashes of the keys are kept in memory.
+type APIKeys struct {
+	keys []apiKey
+}
+
+// ParseAPIKeys reads keys given as subject:role:key, one per entry.
+func ParseAPIKeys(entries []string) (*APIKeys, error) {
+	a := &APIKeys{}
+
+	for i, e := range entries {
+		parts := strings.SplitN(strings.TrimSpace(e), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("api key %d: must look like subject:role:key", i+1)
+		}
+
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("api key %d: %w", i+1, err)
+		}
+
+		a.keys = append(a.keys, apiKey{
+			hash:      sha256.Sum256([]byte(parts[2])),
+			principal: Principal{Subject: parts[0], Role: role},
+		})
+	}
+
+	return a, nil
+}
+
+// ReadAPIKeys reads subject:role:key entries from r, one per line. Blank
+// lines and lines starting with # are skipped.
+func ReadAPIKeys(r io.Reader) ([]string, error) {
+	var entries []string
+
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, line)
+	}
+
+	return entries, s.Err()
+}
+
+func (a *APIKeys) Len() int {
+	return len(a.keys)
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	var found *Principal
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], a.keys[i].hash[:]) == 1 {
+			p := a.keys[i].principal
+			found = &p
+		}
+	}
+
+	if found == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return found, nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+type Role string
+
+const (
+	RoleViewer    Role = "viewer"
+	RoleRecruiter Role = "recruiter"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer:    1,
+	RoleRecruiter: 2,
+	RoleAdmin:     3,
+}
+
+func ParseRole(s string) (Role, error) {
+	r := Role(s)
+	if _, ok := roleRanks[r]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+
+	return r, nil
+}
+
+// Allows reports whether r grants at least the permissions of required.
+// Roles are ordered viewer < recruiter < admin.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] > 0 && roleRanks[r] >= roleRanks[required]
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Role    Role
+}
+
+// Authenticator identifies the caller of r. It returns ErrNoCredentials
+// when r carries no credentials it understands and ErrInvalidCredentials
+// when they don't check out.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Chain tries each authenticator in turn until one recognises the
+// credentials on the request.
+type Chain []Authenticator
+
+func (ch Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range ch {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+
+		return p, err
+	}
+
+	return nil, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or nil for
+// unauthenticated requests.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return p
+}
@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role     Role
+		required Role
+		allowed  bool
+	}{
+		{RoleViewer, RoleViewer, true},
+		{RoleViewer, RoleRecruiter, false},
+		{RoleRecruiter, RoleViewer, true},
+		{RoleRecruiter, RoleAdmin, false},
+		{RoleAdmin, RoleRecruiter, true},
+		{Role("root"), RoleViewer, false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			assert.Equal(t, test.allowed, test.role.Allows(test.required))
+		})
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys([]string{"alice:recruiter:s3cret", "ops:admin:with:colons"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		key       string
+		principal *Principal
+		err       error
+	}{
+		{"s3cret", &Principal{Subject: "alice", Role: RoleRecruiter}, nil},
+		{"with:colons", &Principal{Subject: "ops", Role: RoleAdmin}, nil},
+		{"wrong", nil, ErrInvalidCredentials},
+		{"", nil, ErrNoCredentials},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/offers", nil)
+			if test.key != "" {
+				req.Header.Set(APIKeyHeader, test.key)
+			}
+
+			p, err := keys.Authenticate(req)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.principal, p)
+		})
+	}
+}
+
+func TestParseAPIKeys(t *testing.T) {
+	for _, entry := range []string{"alice:recruiter", "alice:root:key", ":admin:key", "alice:admin:"} {
+		t.Run(entry, func(t *testing.T) {
+			_, err := ParseAPIKeys([]string{entry})
+			assert.Error(t, err)
+		})
+	}
+
+	entries, err := ReadAPIKeys(strings.NewReader("# keys\nalice:viewer:a\n\n  bob:admin:b  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice:viewer:a", "bob:admin:b"}, entries)
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	publicKey, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	verifier, err := NewJWT(JWTConfig{
+		HMACSecret:   secret,
+		RSAPublicKey: publicKey,
+		Issuer:       "offers-test",
+	})
+	require.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":  "alice",
+			"role": "recruiter",
+			"iss":  "offers-test",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+
+		return "Bearer " + s
+	}
+
+	without := func(claim string) jwt.MapClaims {
+		c := valid()
+		delete(c, claim)
+
+		return c
+	}
+
+	with := func(claim string, v interface{}) jwt.MapClaims {
+		c := valid()
+		c[claim] = v
+
+		return c
+	}
+
+	alice := &Principal{Subject: "alice", Role: RoleRecruiter}
+
+	tests := []struct {
+		name      string
+		header    string
+		principal *Principal
+		err       error
+	}{
+		{"hs256", sign(jwt.SigningMethodHS256, secret, valid()), alice, nil},
+		{"rs256", sign(jwt.SigningMethodRS256, rsaKey, valid()), alice, nil},
+		{"lowercase scheme", strings.Replace(sign(jwt.SigningMethodHS256, secret, valid()), "Bearer", "bearer", 1), alice, nil},
+		{"no header", "", nil, ErrNoCredentials},
+		{"basic auth", "Basic YWxpY2U6c2VjcmV0", nil, ErrNoCredentials},
+		{"wrong secret", sign(jwt.SigningMethodHS256, []byte("another secret"), valid()), nil, ErrInvalidCredentials},
+		{"wrong rsa key", sign(jwt.SigningMethodRS256, otherKey, valid()), nil, ErrInvalidCredentials},
+		{"hs256 signed with public key", sign(jwt.SigningMethodHS256, der, valid()), nil, ErrInvalidCredentials},
+		{"unsupported algorithm", sign(jwt.SigningMethodHS512, secret, valid()), nil, ErrInvalidCredentials},
+		{"expired", sign(jwt.SigningMethodHS256, secret, with("exp", time.Now().Add(-time.Minute).Unix())), nil, ErrInvalidCredentials},
+		{"no expiry", sign(jwt.SigningMethodHS256, secret, without("exp")), nil, ErrInvalidCredentials},
+		{"no subject", sign(jwt.SigningMethodHS256, secret, without("sub")), nil, ErrInvalidCredentials},
+		{"wrong issuer", sign(jwt.SigningMethodHS256, secret, with("iss", "someone-else")), nil, ErrInvalidCredentials},
+		{"unknown role", sign(jwt.SigningMethodHS256, secret, with("role", "root")), nil, ErrInvalidCredentials},
+		{"garbage", "Bearer not.a.token", nil, ErrInvalidCredentials},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/offers", nil)
+			if test.header != "" {
+				req.Header.Set("Authorization", test.header)
+			}
+
+			p, err := verifier.Authenticate(req)
+
+			assert.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.principal, p)
+		})
+	}
+}
+
+func TestNewJWTWithoutKeys(t *testing.T) {
+	_, err := NewJWT(JWTConfig{})
+	assert.Error(t, err)
+}
+
+func TestChain(t *testing.T) {
+	keys, err := ParseAPIKeys([]string{"ops:admin:key"})
+	require.NoError(t, err)
+
+	verifier, err := NewJWT(JWTConfig{HMACSecret: []byte("secret")})
+	require.NoError(t, err)
+
+	chain := Chain{keys, verifier}
+
+	req := httptest.NewRequest("GET", "/offers", nil)
+	_, err = chain.Authenticate(req)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	req.Header.Set("Authorization", "Bearer nope")
+	_, err = chain.Authenticate(req)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	req.Header.Set(APIKeyHeader, "key")
+	p, err := chain.Authenticate(req)
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "ops", Role: RoleAdmin}, p)
+}
@@ -0,0 +1,103 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultInterval  = time.Minute
+	DefaultBatchSize = 500
+)
+
+type Sweeper struct {
+	expirer   offers.ExpireOffers
+	interval  time.Duration
+	batchSize int
+	lg        *zap.SugaredLogger
+}
+
+func NewSweeper(e offers.ExpireOffers, interval time.Duration, batchSize int, lg *zap.SugaredLogger) *Sweeper {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &Sweeper{
+		expirer:   e,
+		interval:  interval,
+		batchSize: batchSize,
+		lg:        lg,
+	}
+}
+
+// Run sweeps expired offers immediately and then on every interval until
+// ctx is cancelled. Failed sweeps are logged and retried on the next tick.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			s.lg.Errorw("expiry sweep failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep soft-deletes every offer expired by now, batch by batch, and returns
+// how many were swept. It holds the expiry lock throughout and backs off
+// quietly when another replica is already sweeping.
+func (s *Sweeper) Sweep(ctx context.Context) (int64, error) {
+	start := time.Now()
+
+	unlock, err := s.expirer.LockExpiry(ctx)
+	if errors.Is(err, storage.ErrLocked) {
+		s.lg.Infow("expiry sweep skipped, another runner holds the lock")
+
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var swept int64
+	var batches int
+
+	for {
+		n, err := s.expirer.Expire(ctx, start, s.batchSize)
+		if err != nil {
+			return swept, err
+		}
+
+		swept += n
+		batches++
+
+		if n < int64(s.batchSize) {
+			break
+		}
+	}
+
+	s.lg.Infow("expiry sweep finished",
+		"swept", swept,
+		"batches", batches,
+		"duration", time.Since(start))
+
+	return swept, nil
+}
@@ -0,0 +1,158 @@
+package expiry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type testExpireOffers struct {
+	expireCalled int
+	batches      []int64
+	errs         []error
+	lockErr      error
+	locked       bool
+}
+
+func (t *testExpireOffers) LockExpiry(ctx context.Context) (func(), error) {
+	if t.lockErr != nil {
+		return nil, t.lockErr
+	}
+
+	t.locked = true
+
+	return func() { t.locked = false }, nil
+}
+
+func (t *testExpireOffers) Expire(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	if !t.locked {
+		return 0, errors.New("expired without holding the lock")
+	}
+
+	i := t.expireCalled
+	t.expireCalled++
+
+	var err error
+	if i < len(t.errs) {
+		err = t.errs[i]
+	}
+
+	if i < len(t.batches) {
+		return t.batches[i], err
+	}
+
+	return 0, err
+}
+
+func TestSweep(t *testing.T) {
+	tests := []struct {
+		batches       []int64
+		errs          []error
+		lockErr       error
+		expectedSwept int64
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			[]int64{0},
+			nil,
+			nil,
+			0,
+			1,
+			nil,
+		},
+		{
+			[]int64{10, 10, 3},
+			nil,
+			nil,
+			23,
+			3,
+			nil,
+		},
+		{
+			[]int64{10, 10},
+			nil,
+			nil,
+			20,
+			3,
+			nil,
+		},
+		{
+			nil,
+			nil,
+			storage.ErrLocked,
+			0,
+			0,
+			nil,
+		},
+		{
+			[]int64{0},
+			[]error{errors.New("oops..something went wrong")},
+			nil,
+			0,
+			1,
+			errors.New("oops..something went wrong"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			observedZapCore, _ := observer.New(zap.InfoLevel)
+
+			e := testExpireOffers{
+				batches: test.batches,
+				errs:    test.errs,
+				lockErr: test.lockErr,
+			}
+
+			swept, err := NewSweeper(&e, time.Minute, 10, zap.New(observedZapCore).Sugar()).Sweep(context.Background())
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedSwept, swept)
+			assert.Equal(t, test.expectedCalls, e.expireCalled)
+			assert.False(t, e.locked, "lock not released")
+		})
+	}
+}
+
+func TestSweepLogsMetrics(t *testing.T) {
+	observedZapCore, observedLogs := observer.New(zap.InfoLevel)
+
+	e := testExpireOffers{batches: []int64{10, 4}}
+
+	_, err := NewSweeper(&e, time.Minute, 10, zap.New(observedZapCore).Sugar()).Sweep(context.Background())
+	assert.Nil(t, err)
+
+	logs := observedLogs.FilterMessage("expiry sweep finished").All()
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, int64(14), logs[0].ContextMap()["swept"])
+	assert.Equal(t, int64(2), logs[0].ContextMap()["batches"])
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	observedZapCore, _ := observer.New(zap.InfoLevel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		NewSweeper(&testExpireOffers{}, time.Millisecond, 10, zap.New(observedZapCore).Sugar()).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after cancellation")
+	}
+}
@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var embedded embed.FS
+
+type Migration struct {
+	Version int64
+	Name    string
+
+	up   string
+	down string
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads migrations for the given SQL dialect from the files embedded
+// in the binary.
+func Load(dialect string) ([]Migration, error) {
+	dir, err := fs.Sub(embedded, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return load(dir)
+}
+
+// load parses every <version>_<name>.(up|down).sql file in fsys and returns
+// the migrations ordered by version.
+func load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, f := range files {
+		m := fileName.FindStringSubmatch(f.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %q: name must look like 0001_description.up.sql", f.Name())
+		}
+
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %q: %w", f.Name(), err)
+		}
+
+		b, err := fs.ReadFile(fsys, f.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, migration.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			migration.up = string(b)
+		} else {
+			migration.down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"0002_add_index.up.sql":    {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0002_add_index.down.sql":  {Data: []byte("DROP INDEX i;")},
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c text);")},
+		"0010_irreversible.up.sql": {Data: []byte("UPDATE t SET c = lower(c);")},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "create_table", up: "CREATE TABLE t (c text);"},
+		{Version: 2, Name: "add_index", up: "CREATE INDEX i ON t (c);", down: "DROP INDEX i;"},
+		{Version: 10, Name: "irreversible", up: "UPDATE t SET c = lower(c);"},
+	}, migrations)
+}
+
+func TestLoadRejectsMalformedMigrations(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"bad name": {
+			"create_table.up.sql": {Data: []byte("CREATE TABLE t (c text);")},
+		},
+		"missing up": {
+			"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		},
+		"conflicting names": {
+			"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c text);")},
+			"0001_drop_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(fsys)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestEmbeddedMigrationsAreReversible(t *testing.T) {
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		t.Run(dialect, func(t *testing.T) {
+			migrations, err := Load(dialect)
+
+			assert.Nil(t, err)
+			assert.NotEmpty(t, migrations)
+
+			for i, m := range migrations {
+				assert.Equal(t, int64(i+1), m.Version, "versions must be sequential")
+				assert.NotEmpty(t, m.down, "migration %d_%s has no down script", m.Version, m.Name)
+			}
+		})
+	}
+}
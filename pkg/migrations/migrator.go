@@ -0,0 +1,283 @@
+package migrations
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"example.com/playground/pkg/api"
+
+	"gorm.io/gorm"
+)
+
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version bigint PRIMARY KEY,
+  name text NOT NULL,
+  applied_at timestamp DEFAULT current_timestamp NOT NULL
+)`
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in version order, each in its own
+// transaction together with its schema_migrations record.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		pending, err := m.pending(db)
+		if err != nil {
+			return err
+		}
+
+		applied = make([]Migration, 0, len(pending))
+
+		for _, migration := range pending {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.up).Error; err != nil {
+					return err
+				}
+
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts up to steps of the most recently applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	reverted := make([]Migration, 0, steps)
+
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int64]Migration, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = migration
+		}
+
+		for i := len(applied) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration, ok := known[applied[i].Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s: not found in this build", applied[i].Version, applied[i].Name)
+			}
+
+			if migration.down == "" {
+				return fmt.Errorf("migration %d_%s: has no down script", migration.Version, migration.Name)
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.down).Error; err != nil {
+					return err
+				}
+
+				return tx.Delete(&schemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// migrationsLockID identifies the advisory lock that keeps replicas from
+// migrating concurrently.
+const migrationsLockID = 7_304_100_452
+
+// locked runs fn on a single connection that holds the migrations lock,
+// once schema_migrations exists. PostgreSQL advisory locks belong to the
+// session, hence the dedicated connection. SQLite lets only one transaction
+// write at a time anyway, so there is no lock to take there.
+func (m *Migrator) locked(ctx context.Context, fn func(db *gorm.DB) error) error {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	db := m.db.WithContext(ctx)
+	db.Statement.ConnPool = conn
+
+	if db.Dialector.Name() == "postgres" {
+		if err := db.Exec("SELECT pg_advisory_lock(?)", migrationsLockID).Error; err != nil {
+			return err
+		}
+
+		defer func() {
+			// Unlock even when ctx is done. Should that fail, the
+			// connection is dropped rather than going back to the pool
+			// still holding the lock.
+			if err := db.WithContext(context.Background()).Exec("SELECT pg_advisory_unlock(?)", migrationsLockID).Error; err != nil {
+				conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			}
+		}()
+	}
+
+	if err := db.Exec(createSchemaMigrations).Error; err != nil {
+		return err
+	}
+
+	return fn(db)
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	return m.status(m.db.WithContext(ctx))
+}
+
+func (m *Migrator) status(db *gorm.DB) ([]Status, error) {
+	applied, err := m.applied(db)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[int64]time.Time, len(applied))
+	for _, a := range applied {
+		appliedAt[a.Version] = a.AppliedAt
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if t, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &t
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Version returns the highest applied migration version, 0 when the schema
+// has never been migrated.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(m.db.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(applied) == 0 {
+		return 0, nil
+	}
+
+	return applied[len(applied)-1].Version, nil
+}
+
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	return m.pending(m.db.WithContext(ctx))
+}
+
+func (m *Migrator) pending(db *gorm.DB) ([]Migration, error) {
+	statuses, err := m.status(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// applied reads schema_migrations without creating it, so that checking the
+// schema needs no DDL rights. A schema that was never migrated has nothing
+// applied.
+func (m *Migrator) applied(db *gorm.DB) ([]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return nil, nil
+	}
+
+	var applied []schemaMigration
+
+	if err := db.
+		Order("version").
+		Find(&applied).
+		Error; err != nil {
+
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// Check reports the schema version and fails while migrations are pending,
+// so that replicas running ahead of the schema aren't sent traffic.
+func (m *Migrator) Check(ctx context.Context) (interface{}, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &api.MigrationStats{}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			stats.Pending++
+		} else {
+			stats.Version = s.Version
+		}
+	}
+
+	if stats.Pending > 0 {
+		return stats, fmt.Errorf("%d migrations pending", stats.Pending)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMigratorSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "offers.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.Nil(t, err)
+
+	m, err := NewMigrator(db)
+	require.Nil(t, err)
+
+	ctx := context.Background()
+
+	pending, err := m.Pending(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.migrations), len(pending))
+
+	// Checking the schema doesn't change it.
+	_, err = m.Check(ctx)
+	assert.NotNil(t, err)
+	assert.False(t, db.Migrator().HasTable("schema_migrations"))
+
+	applied, err := m.Up(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, pending, applied)
+
+	version, err := m.Version(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(m.migrations)), version)
+
+	status, err := m.Status(ctx)
+	assert.Nil(t, err)
+	for _, s := range status {
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	reverted, err := m.Down(ctx, len(m.migrations))
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.migrations), len(reverted))
+
+	version, err = m.Version(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), version)
+}
@@ -1,5 +1,3 @@
-BEGIN;
-
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 CREATE TABLE IF NOT EXISTS public.job_offers (
@@ -11,10 +9,14 @@ CREATE TABLE IF NOT EXISTS public.job_offers (
   link_to_offer text,
   details text,
   phone text,
-  salary double precision NOT NULL, 
+  salary double precision NOT NULL,
   created_at timestamp without time zone DEFAULT current_timestamp NOT NULL,
   deleted_at timestamp without time zone,
   updated_at timestamp without time zone DEFAULT current_timestamp NOT NULL
 );
 
-COMMIT;
+CREATE INDEX IF NOT EXISTS job_offers_company_idx ON public.job_offers (lower(company));
+CREATE INDEX IF NOT EXISTS job_offers_salary_idx ON public.job_offers (salary);
+CREATE INDEX IF NOT EXISTS job_offers_expiration_date_idx ON public.job_offers (expiration_date);
+CREATE INDEX IF NOT EXISTS job_offers_search_idx ON public.job_offers
+  USING GIN (to_tsvector('english', coalesce(company, '') || ' ' || coalesce(details, '')));
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS public.idx_job_offers_deleted_at;
@@ -0,0 +1,3 @@
+-- gorm.Model declares an index on deleted_at, which every soft-delete aware
+-- query filters on.
+CREATE INDEX IF NOT EXISTS idx_job_offers_deleted_at ON public.job_offers (deleted_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.job_offers_owner_id_idx;
+
+ALTER TABLE public.job_offers DROP COLUMN IF EXISTS owner_id;
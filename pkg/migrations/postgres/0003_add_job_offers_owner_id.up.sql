@@ -0,0 +1,5 @@
+-- owner_id records the principal that created an offer. Offers created
+-- before ownership existed have no owner and can only be changed by admins.
+ALTER TABLE public.job_offers ADD COLUMN IF NOT EXISTS owner_id TEXT;
+
+CREATE INDEX IF NOT EXISTS job_offers_owner_id_idx ON public.job_offers (owner_id);
@@ -0,0 +1 @@
+ALTER TABLE public.job_offers DROP COLUMN IF EXISTS version;
@@ -0,0 +1,3 @@
+-- version is bumped on every change and backs the ETag of an offer, letting
+-- clients make conditional updates.
+ALTER TABLE public.job_offers ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
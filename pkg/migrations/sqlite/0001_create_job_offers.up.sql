@@ -0,0 +1,20 @@
+-- Offers get their uuid from the application, SQLite has no generator for
+-- them. Timestamps are stored as text in UTC, which keeps them sortable.
+CREATE TABLE IF NOT EXISTS job_offers (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  uuid text UNIQUE NOT NULL,
+  company text,
+  email text,
+  expiration_date datetime,
+  link_to_offer text,
+  details text,
+  phone text,
+  salary real NOT NULL,
+  created_at datetime DEFAULT current_timestamp NOT NULL,
+  deleted_at datetime,
+  updated_at datetime DEFAULT current_timestamp NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS job_offers_company_idx ON job_offers (lower(company));
+CREATE INDEX IF NOT EXISTS job_offers_salary_idx ON job_offers (salary);
+CREATE INDEX IF NOT EXISTS job_offers_expiration_date_idx ON job_offers (expiration_date);
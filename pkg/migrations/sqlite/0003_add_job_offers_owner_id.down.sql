@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS job_offers_owner_id_idx;
+
+ALTER TABLE job_offers DROP COLUMN owner_id;
@@ -0,0 +1,5 @@
+-- owner_id records the principal that created an offer. Offers created
+-- before ownership existed have no owner and can only be changed by admins.
+ALTER TABLE job_offers ADD COLUMN owner_id text;
+
+CREATE INDEX IF NOT EXISTS job_offers_owner_id_idx ON job_offers (owner_id);
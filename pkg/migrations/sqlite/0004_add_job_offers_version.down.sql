@@ -0,0 +1 @@
+ALTER TABLE job_offers DROP COLUMN version;
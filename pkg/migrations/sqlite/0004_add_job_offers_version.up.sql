@@ -0,0 +1,3 @@
+-- version is bumped on every change and backs the ETag of an offer, letting
+-- clients make conditional updates.
+ALTER TABLE job_offers ADD COLUMN version integer NOT NULL DEFAULT 1;
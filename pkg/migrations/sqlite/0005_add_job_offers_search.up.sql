@@ -0,0 +1,28 @@
+-- SQLite has no full-text search over expressions, q is matched against an
+-- FTS4 index of company and details instead, kept in step by triggers. The
+-- unicode61 tokenizer splits on anything but letters and digits and folds
+-- case, like the word matching of the other backends.
+CREATE VIRTUAL TABLE IF NOT EXISTS job_offers_search USING fts4(
+  content="job_offers",
+  company,
+  details,
+  tokenize=unicode61
+);
+
+CREATE TRIGGER IF NOT EXISTS job_offers_search_insert AFTER INSERT ON job_offers BEGIN
+  INSERT INTO job_offers_search(docid, company, details) VALUES (new.id, new.company, new.details);
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_offers_search_before_update BEFORE UPDATE ON job_offers BEGIN
+  DELETE FROM job_offers_search WHERE docid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_offers_search_after_update AFTER UPDATE ON job_offers BEGIN
+  INSERT INTO job_offers_search(docid, company, details) VALUES (new.id, new.company, new.details);
+END;
+
+CREATE TRIGGER IF NOT EXISTS job_offers_search_delete BEFORE DELETE ON job_offers BEGIN
+  DELETE FROM job_offers_search WHERE docid = old.id;
+END;
+
+INSERT INTO job_offers_search(job_offers_search) VALUES ('rebuild');
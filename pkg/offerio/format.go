@@ -0,0 +1,37 @@
+package offerio
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a file format offers are exchanged in.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+var ErrUnknownFormat = errors.New("unknown format, expected csv or jsonl")
+
+// Columns are the CSV header names of the offer fields. They are the JSON
+// names of the api.JobOfferRequest fields.
+var Columns = []string{"company", "email", "expiration_date", "link", "details", "salary", "phone"}
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return FormatCSV, nil
+	case "jsonl", "ndjson":
+		return FormatJSONL, nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// FormatFromPath tells the format of a file by its extension.
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
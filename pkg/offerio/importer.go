@@ -0,0 +1,199 @@
+package offerio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"go.uber.org/zap"
+)
+
+const DefaultBatchSize = 100
+
+var (
+	ErrInvalidRows = errors.New("file has invalid rows")
+	ErrFailedRows  = errors.New("some rows could not be stored")
+)
+
+type ImportOptions struct {
+	BatchSize int
+
+	// DryRun reads and validates the file without writing anything.
+	DryRun bool
+
+	// SkipInvalid imports the valid rows of a file that has invalid ones,
+	// and keeps going past rows the storage rejects. Otherwise nothing is
+	// written when a row is invalid and the import stops at the first batch
+	// that fails, which is rolled back.
+	SkipInvalid bool
+}
+
+// ImportSummary counts the rows of an import file by outcome.
+type ImportSummary struct {
+	Rows     int
+	Invalid  int
+	Imported int
+	Failed   int
+}
+
+type Importer struct {
+	creator offers.BatchCreateOffers
+	opts    ImportOptions
+	lg      *zap.SugaredLogger
+}
+
+func NewImporter(c offers.BatchCreateOffers, opts ImportOptions, lg *zap.SugaredLogger) *Importer {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	if opts.BatchSize > api.MaxBatchSize {
+		opts.BatchSize = api.MaxBatchSize
+	}
+
+	return &Importer{
+		creator: c,
+		opts:    opts,
+		lg:      lg,
+	}
+}
+
+// Import validates every row of r, logging the ones that are invalid, and
+// then stores the valid ones batch by batch. Each batch is committed on its
+// own, so an import that fails halfway leaves the earlier batches in place.
+func (im *Importer) Import(ctx context.Context, r Reader) (*ImportSummary, error) {
+	start := time.Now()
+	summary := &ImportSummary{}
+
+	var records []*Record
+
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return summary, err
+		}
+
+		summary.Rows++
+
+		rec.Err = validateRecord(rec)
+
+		if rec.Err != nil {
+			summary.Invalid++
+			im.lg.Warnw("invalid row", "row", rec.Row, "error", rec.Err.Error())
+
+			continue
+		}
+
+		records = append(records, rec)
+	}
+
+	if summary.Invalid > 0 && !im.opts.SkipInvalid {
+		im.logSummary("import rejected", summary, start)
+
+		return summary, fmt.Errorf("%w: %d of %d", ErrInvalidRows, summary.Invalid, summary.Rows)
+	}
+
+	if im.opts.DryRun {
+		im.lg.Infow("dry run, nothing was written", "valid", len(records))
+		im.logSummary("import checked", summary, start)
+
+		return summary, nil
+	}
+
+	for i := 0; i < len(records); i += im.opts.BatchSize {
+		end := i + im.opts.BatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+
+		if err := im.importBatch(ctx, records[i:end], summary); err != nil {
+			im.logSummary("import stopped", summary, start)
+
+			return summary, err
+		}
+
+		im.lg.Infow("imported batch",
+			"imported", summary.Imported,
+			"failed", summary.Failed,
+			"total", len(records))
+	}
+
+	im.logSummary("import finished", summary, start)
+
+	if summary.Failed > 0 {
+		return summary, fmt.Errorf("%w: %d of %d", ErrFailedRows, summary.Failed, summary.Rows)
+	}
+
+	return summary, nil
+}
+
+func (im *Importer) importBatch(ctx context.Context, batch []*Record, summary *ImportSummary) error {
+	reqs := make([]*api.JobOfferRequest, len(batch))
+	for i, rec := range batch {
+		reqs[i] = rec.Offer
+	}
+
+	results, err := im.creator.CreateBatch(ctx, reqs, im.opts.SkipInvalid)
+	if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+		return err
+	}
+
+	for i, r := range results {
+		if r.Err != nil {
+			summary.Failed++
+			im.lg.Warnw("row not stored", "row", batch[i].Row, "error", r.Err.Error())
+		} else if err == nil {
+			summary.Imported++
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: batch starting at row %d rolled back", ErrFailedRows, batch[0].Row)
+	}
+
+	return nil
+}
+
+// validateRecord adds the validation errors of the offer to the ones met
+// while parsing it.
+func validateRecord(rec *Record) error {
+	if rec.Offer == nil {
+		return rec.Err
+	}
+
+	err := rec.Offer.Validate()
+	if rec.Err == nil {
+		return err
+	}
+
+	var parsed, validated validation.Errors
+	if !errors.As(rec.Err, &parsed) || !errors.As(err, &validated) {
+		return rec.Err
+	}
+
+	for field, e := range parsed {
+		validated[field] = e
+	}
+
+	return validated
+}
+
+func (im *Importer) logSummary(msg string, summary *ImportSummary, start time.Time) {
+	im.lg.Infow(msg,
+		"rows", summary.Rows,
+		"invalid", summary.Invalid,
+		"imported", summary.Imported,
+		"failed", summary.Failed,
+		"duration", time.Since(start))
+}
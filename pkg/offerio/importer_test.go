@@ -0,0 +1,197 @@
+package offerio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testBatchCreateOffers rejects offers of the company "TAKEN" and rolls
+// the batch back unless it is best effort.
+type testBatchCreateOffers struct {
+	batchCalled int
+	batchSizes  []int
+	err         error
+}
+
+func (b *testBatchCreateOffers) CreateBatch(_ context.Context, reqs []*api.JobOfferRequest, bestEffort bool) ([]offers.BatchResult, error) {
+	b.batchCalled++
+	b.batchSizes = append(b.batchSizes, len(reqs))
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var failed bool
+
+	results := make([]offers.BatchResult, len(reqs))
+	for i, req := range reqs {
+		if req.Company == "TAKEN" {
+			results[i].Err = storage.ErrConflict
+			failed = true
+		} else {
+			results[i].Offer = &api.JobOfferResponse{Company: req.Company}
+		}
+	}
+
+	if failed && !bestEffort {
+		return results, storage.ErrBatchFailed
+	}
+
+	return results, nil
+}
+
+func csvFile(companies ...string) string {
+	var b strings.Builder
+	b.WriteString("company,email,details,link,salary,phone\n")
+
+	for _, company := range companies {
+		fmt.Fprintf(&b, "%s,hr@acme.com,Go,http://acme.com,5000,+38978653534\n", company)
+	}
+
+	return b.String()
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		file            string
+		opts            ImportOptions
+		storageErr      error
+		expectedSummary ImportSummary
+		expectedSizes   []int
+		expectedErr     error
+	}{
+		{
+			csvFile("A", "B", "C", "D", "E"),
+			ImportOptions{BatchSize: 2},
+			nil,
+			ImportSummary{Rows: 5, Imported: 5},
+			[]int{2, 2, 1},
+			nil,
+		},
+		{
+			csvFile("A", "", "C"),
+			ImportOptions{BatchSize: 2},
+			nil,
+			ImportSummary{Rows: 3, Invalid: 1},
+			nil,
+			ErrInvalidRows,
+		},
+		{
+			csvFile("A", "", "C"),
+			ImportOptions{BatchSize: 2, SkipInvalid: true},
+			nil,
+			ImportSummary{Rows: 3, Invalid: 1, Imported: 2},
+			[]int{2},
+			nil,
+		},
+		{
+			csvFile("A", "B", "C"),
+			ImportOptions{BatchSize: 2, DryRun: true},
+			nil,
+			ImportSummary{Rows: 3},
+			nil,
+			nil,
+		},
+		{
+			csvFile("A", ""),
+			ImportOptions{DryRun: true},
+			nil,
+			ImportSummary{Rows: 2, Invalid: 1},
+			nil,
+			ErrInvalidRows,
+		},
+		{
+			csvFile("A", "B", "TAKEN", "D", "E"),
+			ImportOptions{BatchSize: 2},
+			nil,
+			ImportSummary{Rows: 5, Imported: 2, Failed: 1},
+			[]int{2, 2},
+			ErrFailedRows,
+		},
+		{
+			csvFile("A", "B", "TAKEN", "D", "E"),
+			ImportOptions{BatchSize: 2, SkipInvalid: true},
+			nil,
+			ImportSummary{Rows: 5, Imported: 4, Failed: 1},
+			[]int{2, 2, 1},
+			ErrFailedRows,
+		},
+		{
+			csvFile("A", "B"),
+			ImportOptions{},
+			errors.New("oops..something went wrong"),
+			ImportSummary{Rows: 2},
+			[]int{2},
+			errors.New("oops..something went wrong"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			observedZapCore, _ := observer.New(zap.InfoLevel)
+
+			b := &testBatchCreateOffers{err: test.storageErr}
+
+			r, err := NewReader(strings.NewReader(test.file), FormatCSV)
+			assert.Nil(t, err)
+
+			summary, err := NewImporter(b, test.opts, zap.New(observedZapCore).Sugar()).Import(context.Background(), r)
+
+			if test.expectedErr == nil {
+				assert.Nil(t, err)
+			} else if !errors.Is(err, test.expectedErr) {
+				assert.Equal(t, test.expectedErr, err)
+			}
+
+			assert.Equal(t, test.expectedSummary, *summary)
+			assert.Equal(t, test.expectedSizes, b.batchSizes)
+		})
+	}
+}
+
+func TestImportLogsRowErrors(t *testing.T) {
+	observedZapCore, observedLogs := observer.New(zap.InfoLevel)
+
+	r, err := NewReader(strings.NewReader(csvFile("A", "", "TAKEN")), FormatCSV)
+	assert.Nil(t, err)
+
+	_, err = NewImporter(&testBatchCreateOffers{}, ImportOptions{SkipInvalid: true}, zap.New(observedZapCore).Sugar()).
+		Import(context.Background(), r)
+	assert.True(t, errors.Is(err, ErrFailedRows))
+
+	invalid := observedLogs.FilterMessage("invalid row").All()
+	assert.Equal(t, 1, len(invalid))
+	assert.Equal(t, int64(3), invalid[0].ContextMap()["row"])
+	assert.Equal(t, "company: cannot be blank.", invalid[0].ContextMap()["error"])
+
+	rejected := observedLogs.FilterMessage("row not stored").All()
+	assert.Equal(t, 1, len(rejected))
+	assert.Equal(t, int64(4), rejected[0].ContextMap()["row"])
+}
+
+func TestImportReportsAllRowErrors(t *testing.T) {
+	observedZapCore, observedLogs := observer.New(zap.InfoLevel)
+
+	file := "company,email,details,link,salary,phone\n,hr@acme.com,Go,http://acme.com,lots,+38978653534\n"
+
+	r, err := NewReader(strings.NewReader(file), FormatCSV)
+	assert.Nil(t, err)
+
+	_, err = NewImporter(nil, ImportOptions{DryRun: true}, zap.New(observedZapCore).Sugar()).Import(context.Background(), r)
+	assert.True(t, errors.Is(err, ErrInvalidRows))
+
+	invalid := observedLogs.FilterMessage("invalid row").All()
+	assert.Equal(t, 1, len(invalid))
+	assert.Equal(t, "company: cannot be blank; salary: must be a number.", invalid[0].ContextMap()["error"])
+}
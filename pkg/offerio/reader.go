@@ -0,0 +1,213 @@
+package offerio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"example.com/playground/pkg/api"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// maxLineBytes caps the length of a JSON Lines record.
+const maxLineBytes = 1 << 20
+
+var (
+	errNotNumber       = errors.New("must be a number")
+	errUnknownColumn   = errors.New("unknown column")
+	errDuplicateColumn = errors.New("column is listed more than once")
+)
+
+// Record is a row of an import file. Err is set when the row couldn't be
+// parsed, along with whatever could be made of the offer.
+type Record struct {
+	// Row is the 1-based line of the record in a JSON Lines file, or its
+	// row in a CSV file with the header as row 1, as a spreadsheet numbers
+	// it.
+	Row   int
+	Offer *api.JobOfferRequest
+	Err   error
+}
+
+// Reader reads offers record by record and returns io.EOF after the last
+// one. Rows that can't be parsed are returned as records with Err set, the
+// error is only for failures that make the rest of the file unreadable.
+type Reader interface {
+	Read() (*Record, error)
+}
+
+func NewReader(r io.Reader, f Format) (Reader, error) {
+	switch f {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatJSONL:
+		return newJSONLReader(r), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	row     int
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	known := make(map[string]bool, len(Columns))
+	for _, c := range Columns {
+		known[c] = true
+	}
+
+	reader := &csvReader{r: cr, row: 1, columns: make(map[string]int)}
+
+	for i, name := range header {
+		// Spreadsheets like to start UTF-8 files with a byte order mark.
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if exportOnly[name] {
+			continue
+		}
+
+		if !known[name] {
+			return nil, fmt.Errorf("CSV header %q: %w", name, errUnknownColumn)
+		}
+
+		if _, ok := reader.columns[name]; ok {
+			return nil, fmt.Errorf("CSV header %q: %w", name, errDuplicateColumn)
+		}
+
+		reader.columns[name] = i
+	}
+
+	return reader, nil
+}
+
+func (r *csvReader) Read() (*Record, error) {
+	fields, err := r.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	r.row++
+	rec := &Record{Row: r.row}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		rec.Err = parseErr.Err
+
+		return rec, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+
+		return strings.TrimSpace(fields[i])
+	}
+
+	offer := &api.JobOfferRequest{
+		Company:        field("company"),
+		Email:          field("email"),
+		ExpirationDate: field("expiration_date"),
+		LinkToOffer:    field("link"),
+		Details:        field("details"),
+		ContactPhone:   field("phone"),
+	}
+
+	// An unparseable salary is left empty, so that validation still goes
+	// over the rest of the row.
+	if s := field("salary"); s != "" {
+		salary, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			rec.Err = validation.Errors{"salary": errNotNumber}
+		}
+
+		offer.Salary = salary
+	}
+
+	rec.Offer = offer
+
+	return rec, nil
+}
+
+// exportOnly are the columns of an export that aren't offer fields.
+var exportOnly = map[string]bool{"uuid": true, "version": true}
+
+// exportedOffer is an offer as the writer exports it, with the fields only
+// an export has ignored.
+type exportedOffer struct {
+	api.JobOfferRequest
+
+	ID      json.RawMessage `json:"uuid"`
+	Version json.RawMessage `json:"version"`
+}
+
+type jsonlReader struct {
+	s    *bufio.Scanner
+	line int
+}
+
+func newJSONLReader(r io.Reader) *jsonlReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLineBytes)
+
+	return &jsonlReader{s: s}
+}
+
+func (r *jsonlReader) Read() (*Record, error) {
+	for r.s.Scan() {
+		r.line++
+
+		line := bytes.TrimSpace(r.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		rec := &Record{Row: r.line}
+
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+
+		var offer exportedOffer
+		if err := dec.Decode(&offer); err != nil {
+			rec.Err = err
+		} else {
+			rec.Offer = &offer.JobOfferRequest
+		}
+
+		return rec, nil
+	}
+
+	if err := r.s.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+
+	return nil, io.EOF
+}
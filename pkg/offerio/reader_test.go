@@ -0,0 +1,128 @@
+package offerio
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, r Reader) []*Record {
+	t.Helper()
+
+	var records []*Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records
+		}
+
+		assert.Nil(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	const file = "\ufeffCompany, Email,salary,phone,link,details,expiration_date\n" +
+		"ACME,hr@acme.com,5000,+38978653534,http://acme.com,\"Go, SQL\",2030-03-01\n" +
+		"ACME,hr@acme.com,lots,+38978653534,http://acme.com,,\n" +
+		"ACME,hr@acme.com\n"
+
+	r, err := NewReader(strings.NewReader(file), FormatCSV)
+	assert.Nil(t, err)
+
+	records := readAll(t, r)
+	assert.Equal(t, 3, len(records))
+
+	assert.Equal(t, 2, records[0].Row)
+	assert.Nil(t, records[0].Err)
+	assert.Equal(t, &api.JobOfferRequest{
+		Company:        "ACME",
+		Email:          "hr@acme.com",
+		ExpirationDate: "2030-03-01",
+		LinkToOffer:    "http://acme.com",
+		Details:        "Go, SQL",
+		Salary:         5000,
+		ContactPhone:   "+38978653534",
+	}, records[0].Offer)
+
+	assert.Equal(t, 3, records[1].Row)
+	assert.EqualError(t, records[1].Err, "salary: must be a number.")
+
+	// Short rows leave the missing fields empty for validation to catch.
+	assert.Equal(t, 4, records[2].Row)
+	assert.Equal(t, &api.JobOfferRequest{Company: "ACME", Email: "hr@acme.com"}, records[2].Offer)
+}
+
+func TestCSVHeader(t *testing.T) {
+	tests := []struct {
+		header      string
+		expectedErr string
+	}{
+		{"company,email", ""},
+		{"company,notes", `CSV header "notes": unknown column`},
+		{"company,Company", `CSV header "company": column is listed more than once`},
+		{"", "reading CSV header: EOF"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(test.header), FormatCSV)
+			if test.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	const file = `{"company": "ACME", "salary": 5000}
+
+{"company": "ACME", "salery": 5000}
+{"company": "ACME"`
+
+	r, err := NewReader(strings.NewReader(file), FormatJSONL)
+	assert.Nil(t, err)
+
+	records := readAll(t, r)
+	assert.Equal(t, 3, len(records))
+
+	assert.Equal(t, 1, records[0].Row)
+	assert.Equal(t, &api.JobOfferRequest{Company: "ACME", Salary: 5000}, records[0].Offer)
+
+	assert.Equal(t, 3, records[1].Row)
+	assert.NotNil(t, records[1].Err)
+
+	assert.Equal(t, 4, records[2].Row)
+	assert.NotNil(t, records[2].Err)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path           string
+		expectedFormat Format
+		expectedErr    error
+	}{
+		{"offers.csv", FormatCSV, nil},
+		{"/tmp/offers.CSV", FormatCSV, nil},
+		{"offers.jsonl", FormatJSONL, nil},
+		{"offers.ndjson", FormatJSONL, nil},
+		{"offers.xlsx", "", ErrUnknownFormat},
+		{"-", "", ErrUnknownFormat},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			format, err := FormatFromPath(test.path)
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedFormat, format)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package offerio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"example.com/playground/pkg/api"
+)
+
+// ExportColumns are the CSV header names of an export: the offer fields
+// framed by the uuid and version of each offer. The reader skips the two,
+// so that exports can be imported again as new offers.
+var ExportColumns = append(append([]string{"uuid"}, Columns...), "version")
+
+// Writer writes offers one by one. Flush has to be called once the last
+// one is written.
+type Writer interface {
+	Write(*api.JobOfferResponse) error
+	Flush() error
+}
+
+func NewWriter(w io.Writer, f Format) (Writer, error) {
+	switch f {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// ContentType is the media type of a file in format f.
+func ContentType(f Format) string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+
+	return "application/x-ndjson"
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(o *api.JobOfferResponse) error {
+	if !w.headerWritten {
+		if err := w.w.Write(ExportColumns); err != nil {
+			return err
+		}
+
+		w.headerWritten = true
+	}
+
+	var expirationDate string
+	if o.ExpirationDate != nil {
+		expirationDate = o.ExpirationDate.UTC().Format(time.RFC3339)
+	}
+
+	return w.w.Write([]string{
+		o.ID,
+		o.Company,
+		o.Email,
+		expirationDate,
+		o.LinkToOffer,
+		o.Details,
+		strconv.FormatFloat(o.Salary, 'f', -1, 64),
+		o.ContactPhone,
+		strconv.FormatInt(o.Version, 10),
+	})
+}
+
+// Flush writes the header too when there were no offers, so that an empty
+// export is still a valid file.
+func (w *csvWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.w.Write(ExportColumns); err != nil {
+			return err
+		}
+
+		w.headerWritten = true
+	}
+
+	w.w.Flush()
+
+	return w.w.Error()
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(o *api.JobOfferResponse) error {
+	return w.enc.Encode(o)
+}
+
+func (w *jsonlWriter) Flush() error { return nil }
@@ -0,0 +1,125 @@
+package offerio
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	expirationDate := time.Date(2030, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	offers := []*api.JobOfferResponse{
+		{
+			ID:             "eca51142-3bf0-4766-baf7-2a168c964024",
+			Company:        "ACME",
+			Email:          "hr@acme.com",
+			ExpirationDate: &expirationDate,
+			LinkToOffer:    "http://acme.com",
+			Details:        "Go, SQL",
+			Salary:         5000.5,
+			ContactPhone:   "+38978653534",
+			Version:        3,
+		},
+		{
+			ID:      "cd8a6d49-10f2-46d4-9447-0887091e9f2e",
+			Company: "TEST",
+			Version: 1,
+		},
+	}
+
+	tests := []struct {
+		format       Format
+		offers       []*api.JobOfferResponse
+		expectedFile string
+	}{
+		{
+			FormatCSV,
+			offers,
+			"uuid,company,email,expiration_date,link,details,salary,phone,version\n" +
+				"eca51142-3bf0-4766-baf7-2a168c964024,ACME,hr@acme.com,2030-03-01T12:30:00Z,http://acme.com,\"Go, SQL\",5000.5,+38978653534,3\n" +
+				"cd8a6d49-10f2-46d4-9447-0887091e9f2e,TEST,,,,,0,,1\n",
+		},
+		{
+			FormatCSV,
+			nil,
+			"uuid,company,email,expiration_date,link,details,salary,phone,version\n",
+		},
+		{
+			FormatJSONL,
+			offers,
+			`{"uuid":"eca51142-3bf0-4766-baf7-2a168c964024","company":"ACME","email":"hr@acme.com","expiration_date":"2030-03-01T12:30:00Z","link":"http://acme.com","details":"Go, SQL","salary":5000.5,"phone":"+38978653534","version":3}` + "\n" +
+				`{"uuid":"cd8a6d49-10f2-46d4-9447-0887091e9f2e","company":"TEST","email":"","expiration_date":null,"link":"","details":"","salary":0,"phone":"","version":1}` + "\n",
+		},
+		{
+			FormatJSONL,
+			nil,
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			var b bytes.Buffer
+
+			w, err := NewWriter(&b, test.format)
+			assert.Nil(t, err)
+
+			for _, o := range test.offers {
+				assert.Nil(t, w.Write(o))
+			}
+
+			assert.Nil(t, w.Flush())
+			assert.Equal(t, test.expectedFile, b.String())
+		})
+	}
+}
+
+// Exports can be imported again as new offers.
+func TestExportRoundTrip(t *testing.T) {
+	expirationDate := time.Date(2030, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	offer := &api.JobOfferResponse{
+		ID:             "eca51142-3bf0-4766-baf7-2a168c964024",
+		Company:        "ACME",
+		Email:          "hr@acme.com",
+		ExpirationDate: &expirationDate,
+		LinkToOffer:    "http://acme.com",
+		Details:        "Go, SQL",
+		Salary:         5000.5,
+		ContactPhone:   "+38978653534",
+		Version:        3,
+	}
+
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			var b bytes.Buffer
+
+			w, err := NewWriter(&b, format)
+			assert.Nil(t, err)
+			assert.Nil(t, w.Write(offer))
+			assert.Nil(t, w.Flush())
+
+			r, err := NewReader(&b, format)
+			assert.Nil(t, err)
+
+			records := readAll(t, r)
+			assert.Equal(t, 1, len(records))
+			assert.Nil(t, records[0].Err)
+			assert.Equal(t, &api.JobOfferRequest{
+				Company:        "ACME",
+				Email:          "hr@acme.com",
+				ExpirationDate: "2030-03-01T12:30:00Z",
+				LinkToOffer:    "http://acme.com",
+				Details:        "Go, SQL",
+				Salary:         5000.5,
+				ContactPhone:   "+38978653534",
+			}, records[0].Offer)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package offers
+
+import (
+	"context"
+
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/storage"
+)
+
+// Authorizer decides whether the caller in ctx may change or delete the
+// offer, returning ErrForbidden or an error of its own if not. It sees the
+// offer as stored.
+type Authorizer func(ctx context.Context, offer *storage.Offer) error
+
+// OwnerOrAdmin lets admins change any offer and everyone else only the ones
+// they created. Without a caller, as when authentication is turned off,
+// there is no one to check against.
+func OwnerOrAdmin(ctx context.Context, offer *storage.Offer) error {
+	p := auth.FromContext(ctx)
+	if p == nil || p.Role.Allows(auth.RoleAdmin) {
+		return nil
+	}
+
+	if !offer.OwnerID.Valid || offer.OwnerID.String != p.Subject {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// check vets an offer before the caller in ctx changes it. A non-zero
+// version must match the stored one.
+func (s *Service) check(ctx context.Context, version int64) storage.Check {
+	return func(offer *storage.Offer) error {
+		if err := s.authorize(ctx, offer); err != nil {
+			return err
+		}
+
+		if version != 0 && offer.Version != version {
+			return ErrVersionMismatch
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+package offers
+
+import (
+	"context"
+	"errors"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/storage"
+)
+
+// BatchResult is the outcome of a single item of a batch.
+type BatchResult struct {
+	Offer *api.JobOfferResponse
+	Err   error
+}
+
+// BatchCreateOffers creates many offers in one transaction. In best-effort
+// mode the items that fail are skipped and the rest are committed,
+// otherwise a single failure rolls everything back and
+// storage.ErrBatchFailed is returned along with the per-item results.
+type BatchCreateOffers interface {
+	CreateBatch(ctx context.Context, reqs []*api.JobOfferRequest, bestEffort bool) ([]BatchResult, error)
+}
+
+// BatchPatchOffers patches many offers in one transaction, with the same
+// modes as BatchCreateOffers.
+type BatchPatchOffers interface {
+	PatchBatch(ctx context.Context, reqs []*api.BatchPatchItem, bestEffort bool) ([]BatchResult, error)
+}
+
+// BatchDeleteOffers deletes many offers in one transaction, with the same
+// modes as BatchCreateOffers.
+type BatchDeleteOffers interface {
+	DeleteBatch(ctx context.Context, reqs []*api.BatchDeleteItem, bestEffort bool) ([]BatchResult, error)
+}
+
+// CreateBatch validates every request first. Invalid ones fail without
+// reaching the repository, and so does the whole batch unless it's
+// best-effort.
+func (s *Service) CreateBatch(ctx context.Context, reqs []*api.JobOfferRequest, bestEffort bool) ([]BatchResult, error) {
+	results := make([]BatchResult, len(reqs))
+	offers := make([]*storage.Offer, len(reqs))
+
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		offer, err := newOffer(ctx, req)
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		offers[i] = offer
+	}
+
+	if !bestEffort {
+		for _, r := range results {
+			if r.Err != nil {
+				return results, storage.ErrBatchFailed
+			}
+		}
+	}
+
+	errs, err := s.repo.CreateBatch(ctx, offers, bestEffort)
+	if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+		return nil, err
+	}
+
+	for i, offer := range offers {
+		if offer == nil {
+			continue
+		}
+
+		results[i].Err = errs[i]
+
+		if errs[i] != nil || err != nil {
+			continue
+		}
+
+		results[i].Offer = response(offer)
+		s.publish(ctx, Event{Type: Created, OfferID: results[i].Offer.ID, Offer: results[i].Offer})
+	}
+
+	return results, err
+}
+
+// PatchBatch validates every item first like CreateBatch does. Items with
+// a version only apply if the offer is still at it.
+func (s *Service) PatchBatch(ctx context.Context, reqs []*api.BatchPatchItem, bestEffort bool) ([]BatchResult, error) {
+	results := make([]BatchResult, len(reqs))
+	offerIDs := make([]string, len(reqs))
+	changes := make([]*storage.Changes, len(reqs))
+	checks := make([]storage.Check, len(reqs))
+
+	for i, req := range reqs {
+		offerIDs[i] = req.ID
+		checks[i] = s.check(ctx, req.Version)
+
+		if err := req.Validate(); err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		c, err := patchChanges(&req.Patch)
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		changes[i] = c
+	}
+
+	if !bestEffort {
+		for _, r := range results {
+			if r.Err != nil {
+				return results, storage.ErrBatchFailed
+			}
+		}
+	}
+
+	offers, errs, err := s.repo.UpdateBatch(ctx, offerIDs, changes, checks, bestEffort)
+	if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+		return nil, err
+	}
+
+	for i := range reqs {
+		if changes[i] == nil {
+			continue
+		}
+
+		results[i].Err = errs[i]
+
+		if errs[i] != nil || err != nil {
+			continue
+		}
+
+		results[i].Offer = response(offers[i])
+		s.publish(ctx, Event{Type: Updated, OfferID: results[i].Offer.ID, Offer: results[i].Offer})
+	}
+
+	return results, err
+}
+
+// DeleteBatch checks the versions of the items that have one like
+// PatchBatch does.
+func (s *Service) DeleteBatch(ctx context.Context, reqs []*api.BatchDeleteItem, bestEffort bool) ([]BatchResult, error) {
+	offerIDs := make([]string, len(reqs))
+	checks := make([]storage.Check, len(reqs))
+
+	for i, req := range reqs {
+		offerIDs[i] = req.ID
+		checks[i] = s.check(ctx, req.Version)
+	}
+
+	errs, err := s.repo.DeleteBatch(ctx, offerIDs, checks, bestEffort)
+	if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(offerIDs))
+
+	for i, offerID := range offerIDs {
+		results[i].Err = errs[i]
+
+		if errs[i] == nil && err == nil {
+			s.publish(ctx, Event{Type: Deleted, OfferID: offerID})
+		}
+	}
+
+	return results, err
+}
@@ -0,0 +1,39 @@
+package offers
+
+import (
+	"context"
+
+	"example.com/playground/pkg/api"
+)
+
+type EventType string
+
+const (
+	Created  EventType = "created"
+	Updated  EventType = "updated"
+	Deleted  EventType = "deleted"
+	Expired  EventType = "expired"
+	Restored EventType = "restored"
+	Purged   EventType = "purged"
+)
+
+// Event tells about a change to offers. Offer is the offer as stored
+// afterwards, nil for deletions. Expiry and purging by retention touch many
+// offers at once, so their events carry how many there were in Count
+// instead.
+type Event struct {
+	Type    EventType
+	OfferID string
+	Offer   *api.JobOfferResponse
+	Count   int64
+}
+
+// Listener is called once a change has been stored, on the goroutine that
+// made it, so it should return quickly. It can't undo the change.
+type Listener func(context.Context, Event)
+
+func (s *Service) publish(ctx context.Context, e Event) {
+	for _, l := range s.listeners {
+		l(ctx, e)
+	}
+}
@@ -0,0 +1,158 @@
+package offers
+
+import (
+	"context"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/storage"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+func response(offer *storage.Offer) *api.JobOfferResponse {
+	resp := &api.JobOfferResponse{
+		ID:             offer.UUID.String(),
+		Company:        offer.Company,
+		Email:          offer.Email,
+		ExpirationDate: offer.ExpirationDate.Ptr(),
+		LinkToOffer:    offer.LinkToOffer.ValueOrZero(),
+		Details:        offer.Details.ValueOrZero(),
+		Salary:         offer.Salary,
+		ContactPhone:   offer.Phone,
+		Version:        offer.Version,
+	}
+
+	if offer.DeletedAt.Valid {
+		resp.DeletedAt = &offer.DeletedAt.Time
+	}
+
+	return resp
+}
+
+func paginationResponse(p *storage.OfferPage) *api.JobOffersPaginationResponse {
+	data := make([]api.JobOfferResponse, 0, len(p.Offers))
+	for i := range p.Offers {
+		data = append(data, *response(&p.Offers[i]))
+	}
+
+	return &api.JobOffersPaginationResponse{
+		TotalCount: p.TotalCount,
+		NextCursor: p.NextCursor,
+		PrevCursor: p.PrevCursor,
+		Data:       data,
+	}
+}
+
+// newOffer builds a new offer, owned by the caller in ctx.
+func newOffer(ctx context.Context, req *api.JobOfferRequest) (*storage.Offer, error) {
+	expirationDate, err := parseExpirationDate(req.ExpirationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := &storage.Offer{
+		Company:        req.Company,
+		Email:          req.Email,
+		ExpirationDate: expirationDate,
+		LinkToOffer:    null.StringFrom(req.LinkToOffer),
+		Details:        null.StringFrom(req.Details),
+		Salary:         req.Salary,
+		Phone:          req.ContactPhone,
+		Version:        1,
+	}
+
+	if p := auth.FromContext(ctx); p != nil {
+		offer.OwnerID = null.StringFrom(p.Subject)
+	}
+
+	return offer, nil
+}
+
+// updateChanges changes every mutable field, optional ones left out are
+// cleared.
+func updateChanges(req *api.UpdateJobOfferRequest) (*storage.Changes, error) {
+	expirationDate, err := parseExpirationDate(req.ExpirationDate)
+	if err != nil {
+		return nil, err
+	}
+
+	linkToOffer := null.StringFrom(req.LinkToOffer)
+	details := null.NewString(req.Details, req.Details != "")
+
+	return &storage.Changes{
+		Company:        &req.Company,
+		Email:          &req.Email,
+		Phone:          &req.ContactPhone,
+		Salary:         &req.Salary,
+		LinkToOffer:    &linkToOffer,
+		Details:        &details,
+		ExpirationDate: &expirationDate,
+	}, nil
+}
+
+// patchChanges changes the fields present in a merge patch. Nulls have
+// already been rejected by validation for every field that can't hold one.
+func patchChanges(req *api.PatchJobOfferRequest) (*storage.Changes, error) {
+	changes := &storage.Changes{
+		Company:     optionalString(req.Company),
+		Email:       optionalString(req.Email),
+		Phone:       optionalString(req.ContactPhone),
+		LinkToOffer: optionalNullString(req.LinkToOffer),
+		Details:     optionalNullString(req.Details),
+	}
+
+	if req.Salary.Set {
+		salary := req.Salary.Float64
+		changes.Salary = &salary
+	}
+
+	if req.ExpirationDate.Set {
+		var expirationDate null.Time
+
+		if req.ExpirationDate.Valid {
+			t, err := api.ParseExpirationDate(req.ExpirationDate.String)
+			if err != nil {
+				return nil, err
+			}
+
+			expirationDate = null.TimeFrom(t)
+		}
+
+		changes.ExpirationDate = &expirationDate
+	}
+
+	return changes, nil
+}
+
+func optionalString(v api.OptionalString) *string {
+	if !v.Set {
+		return nil
+	}
+
+	return &v.String
+}
+
+func optionalNullString(v api.OptionalString) *null.String {
+	if !v.Set {
+		return nil
+	}
+
+	s := null.NewString(v.String, v.Valid)
+
+	return &s
+}
+
+// parseExpirationDate leaves the expiration date unset when s is empty.
+func parseExpirationDate(s string) (null.Time, error) {
+	if s == "" {
+		return null.Time{}, nil
+	}
+
+	t, err := api.ParseExpirationDate(s)
+	if err != nil {
+		return null.Time{}, err
+	}
+
+	return null.TimeFrom(t), nil
+}
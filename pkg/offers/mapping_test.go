@@ -0,0 +1,81 @@
+package offers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestUpdateChanges(t *testing.T) {
+	changes, err := updateChanges(&api.UpdateJobOfferRequest{
+		Company:        "ACME",
+		Email:          "hr@acme.com",
+		ExpirationDate: "2030-03-01",
+		LinkToOffer:    "http://acme.com/jobs",
+		Salary:         5000,
+		ContactPhone:   "+38978653534",
+	})
+	assert.Nil(t, err)
+
+	company, email, phone, salary := "ACME", "hr@acme.com", "+38978653534", 5000.0
+	link, details := null.StringFrom("http://acme.com/jobs"), null.String{}
+	expirationDate := null.TimeFrom(time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	// Every mutable field is changed, optional ones left out are cleared.
+	assert.Equal(t, &storage.Changes{
+		Company:        &company,
+		Email:          &email,
+		Phone:          &phone,
+		Salary:         &salary,
+		LinkToOffer:    &link,
+		Details:        &details,
+		ExpirationDate: &expirationDate,
+	}, changes)
+
+	_, err = updateChanges(&api.UpdateJobOfferRequest{ExpirationDate: "soon"})
+	assert.NotNil(t, err)
+}
+
+func TestPatchChanges(t *testing.T) {
+	company, salary := "ACME", 9000.0
+	expirationDate := null.TimeFrom(time.Date(2030, 3, 1, 12, 30, 0, 0, time.UTC))
+
+	tests := []struct {
+		patch           string
+		expectedChanges *storage.Changes
+	}{
+		{
+			`{}`,
+			&storage.Changes{},
+		},
+		{
+			`{"salary": 9000, "company": "ACME"}`,
+			&storage.Changes{Company: &company, Salary: &salary},
+		},
+		{
+			`{"details": null, "expiration_date": null}`,
+			&storage.Changes{Details: &null.String{}, ExpirationDate: &null.Time{}},
+		},
+		{
+			`{"expiration_date": "2030-03-01T14:30:00+02:00"}`,
+			&storage.Changes{ExpirationDate: &expirationDate},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.patch, func(t *testing.T) {
+			var req api.PatchJobOfferRequest
+			assert.Nil(t, json.Unmarshal([]byte(test.patch), &req))
+
+			changes, err := patchChanges(&req)
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedChanges, changes)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package offers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/storage"
+)
+
+var (
+	ErrForbidden       = errors.New("offer belongs to someone else")
+	ErrVersionMismatch = errors.New("offer has been modified in the meantime")
+	ErrExpired         = errors.New("offer has expired")
+)
+
+type CreateOffer interface {
+	Create(context.Context, *api.JobOfferRequest) (*api.JobOfferResponse, error)
+}
+
+type GetOffer interface {
+	Get(context.Context, string) (*api.JobOfferResponse, error)
+}
+
+type GetAllOffers interface {
+	GetAll(context.Context, *api.PageRequest, *api.OffersFilter) (*api.JobOffersPaginationResponse, error)
+}
+
+// UpdateOffer takes the version the caller last saw. A non-zero version
+// makes the change conditional on the offer still being at that version,
+// failing with ErrVersionMismatch otherwise.
+type UpdateOffer interface {
+	Update(context.Context, string, int64, *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error)
+}
+
+// PatchOffer takes a version like UpdateOffer does.
+type PatchOffer interface {
+	Patch(context.Context, string, int64, *api.PatchJobOfferRequest) (*api.JobOfferResponse, error)
+}
+
+// DeleteOffer takes a version like UpdateOffer does.
+type DeleteOffer interface {
+	DeleteByID(context.Context, string, int64) error
+}
+
+// ExportOffers hands every offer matching the filter to fn, in the order
+// they were created. Returning an error from fn stops the export.
+type ExportOffers interface {
+	Export(ctx context.Context, filter *api.OffersFilter, fn func(*api.JobOfferResponse) error) error
+}
+
+// ExpireOffers soft-deletes up to batchSize offers that expired before the
+// given time and reports how many were swept. A sweep takes LockExpiry
+// first and releases it when done; it fails with storage.ErrLocked while
+// another runner is sweeping.
+type ExpireOffers interface {
+	LockExpiry(context.Context) (func(), error)
+	Expire(context.Context, time.Time, int) (int64, error)
+}
+
+// Config customises a Service. The zero value is usable.
+type Config struct {
+	// Authorize decides whether the caller may change or delete an offer,
+	// OwnerOrAdmin when left nil.
+	Authorize Authorizer
+
+	// Listeners are told about every change once it has been stored.
+	Listeners []Listener
+}
+
+// Service holds the business rules for offers: it validates requests,
+// makes sure callers may change what they ask to change, keeps track of
+// versions, publishes events and maps offers to responses. Storing them is
+// left to the repository.
+type Service struct {
+	repo      storage.OfferRepository
+	authorize Authorizer
+	listeners []Listener
+}
+
+func NewService(repo storage.OfferRepository, cfg *Config) *Service {
+	s := &Service{repo: repo, authorize: OwnerOrAdmin}
+
+	if cfg != nil {
+		if cfg.Authorize != nil {
+			s.authorize = cfg.Authorize
+		}
+
+		s.listeners = cfg.Listeners
+	}
+
+	return s
+}
+
+func (s *Service) Create(ctx context.Context, req *api.JobOfferRequest) (*api.JobOfferResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	offer, err := newOffer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, offer); err != nil {
+		return nil, err
+	}
+
+	resp := response(offer)
+	s.publish(ctx, Event{Type: Created, OfferID: resp.ID, Offer: resp})
+
+	return resp, nil
+}
+
+func (s *Service) Get(ctx context.Context, offerID string) (*api.JobOfferResponse, error) {
+	offer, err := s.repo.Get(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return response(offer), nil
+}
+
+func (s *Service) GetAll(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*api.JobOffersPaginationResponse, error) {
+	p, err := s.repo.List(ctx, page, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginationResponse(p), nil
+}
+
+// Update replaces every mutable field of the offer and returns the offer as
+// stored afterwards.
+func (s *Service) Update(ctx context.Context, offerID string, version int64, req *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	changes, err := updateChanges(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.change(ctx, offerID, version, changes)
+}
+
+// Patch applies a merge patch to the offer and returns the offer as stored
+// afterwards. An empty patch leaves the version alone.
+func (s *Service) Patch(ctx context.Context, offerID string, version int64, req *api.PatchJobOfferRequest) (*api.JobOfferResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	changes, err := patchChanges(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.change(ctx, offerID, version, changes)
+}
+
+func (s *Service) change(ctx context.Context, offerID string, version int64, changes *storage.Changes) (*api.JobOfferResponse, error) {
+	offer, err := s.repo.Update(ctx, offerID, changes, s.check(ctx, version))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response(offer)
+	s.publish(ctx, Event{Type: Updated, OfferID: resp.ID, Offer: resp})
+
+	return resp, nil
+}
+
+func (s *Service) DeleteByID(ctx context.Context, offerID string, version int64) error {
+	if err := s.repo.Delete(ctx, offerID, s.check(ctx, version)); err != nil {
+		return err
+	}
+
+	s.publish(ctx, Event{Type: Deleted, OfferID: offerID})
+
+	return nil
+}
+
+func (s *Service) Export(ctx context.Context, filter *api.OffersFilter, fn func(*api.JobOfferResponse) error) error {
+	return s.repo.Each(ctx, filter, func(offer *storage.Offer) error {
+		return fn(response(offer))
+	})
+}
+
+func (s *Service) LockExpiry(ctx context.Context) (func(), error) {
+	return s.repo.LockExpiry(ctx)
+}
+
+func (s *Service) Expire(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	swept, err := s.repo.Expire(ctx, before, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if swept > 0 {
+		s.publish(ctx, Event{Type: Expired, Count: swept})
+	}
+
+	return swept, nil
+}
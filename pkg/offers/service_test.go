@@ -0,0 +1,334 @@
+package offers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/storage"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func as(subject string, role auth.Role) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{Subject: subject, Role: role})
+}
+
+func offerRequest(company string) *api.JobOfferRequest {
+	return &api.JobOfferRequest{
+		Company:      company,
+		Email:        "hr@acme.com",
+		LinkToOffer:  "http://acme.com/jobs",
+		Details:      "Go, SQL",
+		Salary:       5000,
+		ContactPhone: "+38978653534",
+	}
+}
+
+func updateRequest(company string) *api.UpdateJobOfferRequest {
+	return &api.UpdateJobOfferRequest{
+		Company:      company,
+		Email:        "hr@acme.com",
+		LinkToOffer:  "http://acme.com/jobs",
+		Salary:       5000,
+		ContactPhone: "+38978653534",
+	}
+}
+
+func TestServiceCreate(t *testing.T) {
+	s := NewService(storage.NewMemoryRepository(nil), nil)
+
+	created, err := s.Create(as("alice", auth.RoleRecruiter), offerRequest("ACME"))
+	require.Nil(t, err)
+	assert.Equal(t, "ACME", created.Company)
+	assert.Equal(t, "Go, SQL", created.Details)
+	assert.Equal(t, int64(1), created.Version)
+
+	offer, err := s.Get(context.Background(), created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, created, offer)
+
+	page, err := s.GetAll(context.Background(), &api.PageRequest{Size: 10}, &api.OffersFilter{Owner: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, []api.JobOfferResponse{*created}, page.Data)
+
+	_, err = s.Create(context.Background(), &api.JobOfferRequest{Company: "ACME"})
+	assert.NotNil(t, err)
+
+	page, err = s.GetAll(context.Background(), &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), *page.TotalCount)
+}
+
+func TestServiceVersions(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(storage.NewMemoryRepository(nil), nil)
+
+	created, err := s.Create(ctx, offerRequest("ACME"))
+	require.Nil(t, err)
+
+	updated, err := s.Update(ctx, created.ID, 1, updateRequest("ACME Corp"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+	// Optional fields left out are cleared.
+	assert.Empty(t, updated.Details)
+
+	_, err = s.Update(ctx, created.ID, 1, updateRequest("Stale"))
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	// Version 0 changes unconditionally.
+	patched, err := s.Patch(ctx, created.ID, 0, &api.PatchJobOfferRequest{Salary: api.OptionalFloat{Set: true, Valid: true, Float64: 1}})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), patched.Version)
+	assert.Equal(t, "ACME Corp", patched.Company)
+
+	// An empty expiration date is invalid rather than an internal error.
+	_, err = s.Patch(ctx, created.ID, 0, &api.PatchJobOfferRequest{ExpirationDate: api.OptionalString{Set: true, Valid: true}})
+	assert.ErrorAs(t, err, &validation.Errors{})
+
+	// An empty patch changes nothing, the version included.
+	patched, err = s.Patch(ctx, created.ID, 3, &api.PatchJobOfferRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), patched.Version)
+
+	assert.ErrorIs(t, s.DeleteByID(ctx, created.ID, 2), ErrVersionMismatch)
+	assert.Nil(t, s.DeleteByID(ctx, created.ID, 3))
+	assert.ErrorIs(t, s.DeleteByID(ctx, created.ID, 0), storage.ErrNotFound)
+}
+
+func TestServiceOwnership(t *testing.T) {
+	alice := as("alice", auth.RoleRecruiter)
+	bob := as("bob", auth.RoleRecruiter)
+	admin := as("root", auth.RoleAdmin)
+
+	s := NewService(storage.NewMemoryRepository(nil), nil)
+
+	created, err := s.Create(alice, offerRequest("ACME"))
+	require.Nil(t, err)
+
+	_, err = s.Update(bob, created.ID, 0, updateRequest("Mine now"))
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, s.DeleteByID(bob, created.ID, 0), ErrForbidden)
+
+	results, err := s.DeleteBatch(bob, []*api.BatchDeleteItem{{ID: created.ID}}, false)
+	assert.ErrorIs(t, err, storage.ErrBatchFailed)
+	assert.ErrorIs(t, results[0].Err, ErrForbidden)
+
+	_, err = s.Update(alice, created.ID, 0, updateRequest("ACME"))
+	assert.Nil(t, err)
+
+	_, err = s.Patch(admin, created.ID, 0, &api.PatchJobOfferRequest{Salary: api.OptionalFloat{Set: true, Valid: true, Float64: 1}})
+	assert.Nil(t, err)
+
+	// Without a caller there is no one to check against.
+	_, err = s.Update(context.Background(), created.ID, 0, updateRequest("ACME"))
+	assert.Nil(t, err)
+}
+
+func TestServiceAuthorizer(t *testing.T) {
+	errClosed := errors.New("offers are closed for changes")
+
+	s := NewService(storage.NewMemoryRepository(nil), &Config{
+		Authorize: func(ctx context.Context, offer *storage.Offer) error {
+			if offer.Company == "Closed" {
+				return errClosed
+			}
+
+			return nil
+		},
+	})
+
+	closed, err := s.Create(as("alice", auth.RoleRecruiter), offerRequest("Closed"))
+	require.Nil(t, err)
+
+	open, err := s.Create(as("alice", auth.RoleRecruiter), offerRequest("Open"))
+	require.Nil(t, err)
+
+	_, err = s.Update(as("bob", auth.RoleRecruiter), closed.ID, 0, updateRequest("Open"))
+	assert.ErrorIs(t, err, errClosed)
+
+	// The authorizer replaces the ownership rule.
+	_, err = s.Update(as("bob", auth.RoleRecruiter), open.ID, 0, updateRequest("Open"))
+	assert.Nil(t, err)
+}
+
+func TestServiceBatch(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(storage.NewMemoryRepository(nil), nil)
+
+	reqs := []*api.JobOfferRequest{offerRequest("ACME"), {Company: "TEST", ExpirationDate: "soon"}}
+
+	results, err := s.CreateBatch(ctx, reqs, false)
+	assert.ErrorIs(t, err, storage.ErrBatchFailed)
+	assert.Nil(t, results[0].Err)
+	assert.Nil(t, results[0].Offer)
+	assert.NotNil(t, results[1].Err)
+
+	page, err := s.GetAll(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Data)
+
+	results, err = s.CreateBatch(ctx, reqs, true)
+	assert.Nil(t, err)
+	assert.NotNil(t, results[0].Offer)
+	assert.NotNil(t, results[1].Err)
+
+	created := results[0].Offer
+
+	salary := api.OptionalFloat{Set: true, Valid: true, Float64: 9000}
+	patches := []*api.BatchPatchItem{
+		{ID: created.ID, Patch: api.PatchJobOfferRequest{Salary: salary}},
+		{ID: "not a uuid", Patch: api.PatchJobOfferRequest{Salary: salary}},
+	}
+
+	results, err = s.PatchBatch(ctx, patches, false)
+	assert.ErrorIs(t, err, storage.ErrBatchFailed)
+	assert.Nil(t, results[0].Offer)
+	assert.NotNil(t, results[1].Err)
+
+	results, err = s.PatchBatch(ctx, patches, true)
+	assert.Nil(t, err)
+	assert.Equal(t, 9000.0, results[0].Offer.Salary)
+	assert.Equal(t, int64(2), results[0].Offer.Version)
+	assert.NotNil(t, results[1].Err)
+
+	// Versions are checked per item.
+	results, err = s.PatchBatch(ctx, []*api.BatchPatchItem{
+		{ID: created.ID, Version: 1, Patch: api.PatchJobOfferRequest{Salary: salary}},
+		{ID: created.ID, Version: 2, Patch: api.PatchJobOfferRequest{Salary: salary}},
+	}, true)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrVersionMismatch)
+	assert.Equal(t, int64(3), results[1].Offer.Version)
+
+	results, err = s.DeleteBatch(ctx, []*api.BatchDeleteItem{{ID: created.ID, Version: 2}}, false)
+	assert.ErrorIs(t, err, storage.ErrBatchFailed)
+	assert.ErrorIs(t, results[0].Err, ErrVersionMismatch)
+
+	results, err = s.DeleteBatch(ctx, []*api.BatchDeleteItem{{ID: created.ID, Version: 3}, {ID: "eca51142-3bf0-4766-baf7-2a168c964024"}}, true)
+	assert.Nil(t, err)
+	assert.Nil(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, storage.ErrNotFound)
+
+	_, err = s.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestServiceEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := storage.NewMemoryRepository(nil)
+
+	var events []Event
+
+	s := NewService(repo, &Config{
+		Listeners: []Listener{func(ctx context.Context, e Event) {
+			events = append(events, e)
+		}},
+	})
+
+	created, err := s.Create(ctx, offerRequest("ACME"))
+	require.Nil(t, err)
+
+	updated, err := s.Update(ctx, created.ID, 0, updateRequest("ACME Corp"))
+	require.Nil(t, err)
+
+	// Failed changes aren't told about.
+	_, err = s.Update(ctx, created.ID, 1, updateRequest("Stale"))
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	require.Nil(t, s.DeleteByID(ctx, created.ID, 0))
+
+	require.Nil(t, repo.Create(ctx, &storage.Offer{Company: "ACME", ExpirationDate: null.TimeFrom(time.Now().Add(-time.Hour))}))
+
+	_, err = s.Expire(ctx, time.Now(), 10)
+	require.Nil(t, err)
+
+	// Sweeping nothing isn't an event either.
+	_, err = s.Expire(ctx, time.Now(), 10)
+	require.Nil(t, err)
+
+	assert.Equal(t, []Event{
+		{Type: Created, OfferID: created.ID, Offer: created},
+		{Type: Updated, OfferID: created.ID, Offer: updated},
+		{Type: Deleted, OfferID: created.ID},
+		{Type: Expired, Count: 1},
+	}, events)
+}
+
+func TestServiceTrash(t *testing.T) {
+	alice := as("alice", auth.RoleRecruiter)
+	bob := as("bob", auth.RoleRecruiter)
+	admin := as("root", auth.RoleAdmin)
+
+	repo := storage.NewMemoryRepository(nil)
+
+	var events []Event
+
+	s := NewService(repo, &Config{
+		Listeners: []Listener{func(ctx context.Context, e Event) {
+			events = append(events, e)
+		}},
+	})
+
+	created, err := s.Create(alice, offerRequest("ACME"))
+	require.Nil(t, err)
+	require.Nil(t, s.DeleteByID(alice, created.ID, 0))
+
+	// Expired offers end up in the trash too, and are listed there by
+	// default.
+	expired := &storage.Offer{Company: "ACME", ExpirationDate: null.TimeFrom(time.Now().Add(-time.Hour))}
+	require.Nil(t, repo.Create(context.Background(), expired))
+
+	_, err = s.Expire(context.Background(), time.Now(), 10)
+	require.Nil(t, err)
+
+	page, err := s.GetTrash(alice, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	require.Equal(t, 2, len(page.Data))
+	assert.Equal(t, created.ID, page.Data[0].ID)
+	assert.NotNil(t, page.Data[0].DeletedAt)
+	assert.Equal(t, expired.UUID.String(), page.Data[1].ID)
+
+	_, err = s.Restore(bob, created.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// Expired offers would only be swept back into the trash.
+	_, err = s.Restore(admin, expired.UUID.String())
+	assert.ErrorIs(t, err, ErrExpired)
+
+	restored, err := s.Restore(alice, created.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), restored.Version)
+	assert.Nil(t, restored.DeletedAt)
+
+	// Only admins may purge, even offers they don't own.
+	assert.ErrorIs(t, s.Purge(alice, created.ID, 0), ErrForbidden)
+	assert.ErrorIs(t, s.Purge(admin, created.ID, 1), ErrVersionMismatch)
+	assert.Nil(t, s.Purge(admin, created.ID, 2))
+
+	_, err = s.Restore(alice, created.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	purged, err := s.PurgeDeleted(context.Background(), time.Now(), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	page, err = s.GetTrash(admin, &api.PageRequest{Size: 10}, nil)
+	assert.Nil(t, err)
+	assert.Empty(t, page.Data)
+
+	assert.Equal(t, []Event{
+		{Type: Created, OfferID: created.ID, Offer: created},
+		{Type: Deleted, OfferID: created.ID},
+		{Type: Expired, Count: 1},
+		{Type: Restored, OfferID: created.ID, Offer: restored},
+		{Type: Purged, OfferID: created.ID},
+		{Type: Purged, Count: 1},
+	}, events)
+}
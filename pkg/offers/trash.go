@@ -0,0 +1,106 @@
+package offers
+
+import (
+	"context"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/storage"
+)
+
+// GetTrash lists the deleted offers. The ones that were deleted because
+// they expired are listed whatever the filter says about expired offers.
+type GetTrash interface {
+	GetTrash(context.Context, *api.PageRequest, *api.OffersFilter) (*api.JobOffersPaginationResponse, error)
+}
+
+// RestoreOffer takes an offer out of the trash. Offers that aren't in the
+// trash aren't found.
+type RestoreOffer interface {
+	Restore(context.Context, string) (*api.JobOfferResponse, error)
+}
+
+// PurgeOffer deletes an offer for good, whether it's in the trash or not.
+// It takes the version the caller last saw like DeleteOffer does.
+type PurgeOffer interface {
+	Purge(context.Context, string, int64) error
+}
+
+// PurgeDeletedOffers deletes up to batchSize offers for good that were
+// moved to the trash before the given time and reports how many there were.
+type PurgeDeletedOffers interface {
+	PurgeDeleted(context.Context, time.Time, int) (int64, error)
+}
+
+func (s *Service) GetTrash(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*api.JobOffersPaginationResponse, error) {
+	trash := api.OffersFilter{}
+	if filter != nil {
+		trash = *filter
+	}
+
+	trash.IncludeExpired = true
+
+	p, err := s.repo.ListDeleted(ctx, page, &trash)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginationResponse(p), nil
+}
+
+// Restore is allowed to whoever may change the offer. Offers past their
+// expiration date fail with ErrExpired, they would only be swept back into
+// the trash.
+func (s *Service) Restore(ctx context.Context, offerID string) (*api.JobOfferResponse, error) {
+	check := s.check(ctx, 0)
+
+	offer, err := s.repo.Restore(ctx, offerID, func(offer *storage.Offer) error {
+		if err := check(offer); err != nil {
+			return err
+		}
+
+		if offer.ExpirationDate.Valid && !offer.ExpirationDate.Time.After(time.Now()) {
+			return ErrExpired
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := response(offer)
+	s.publish(ctx, Event{Type: Restored, OfferID: resp.ID, Offer: resp})
+
+	return resp, nil
+}
+
+// Purge is only allowed to admins, or to anyone when there is no caller,
+// as when authentication is turned off.
+func (s *Service) Purge(ctx context.Context, offerID string, version int64) error {
+	if p := auth.FromContext(ctx); p != nil && !p.Role.Allows(auth.RoleAdmin) {
+		return ErrForbidden
+	}
+
+	if err := s.repo.Purge(ctx, offerID, s.check(ctx, version)); err != nil {
+		return err
+	}
+
+	s.publish(ctx, Event{Type: Purged, OfferID: offerID})
+
+	return nil
+}
+
+func (s *Service) PurgeDeleted(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	purged, err := s.repo.PurgeDeleted(ctx, before, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		s.publish(ctx, Event{Type: Purged, Count: purged})
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/playground/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errUnauthenticated = errors.New("authentication required")
+	errBadCredentials  = errors.New("invalid credentials")
+	errForbidden       = errors.New("insufficient role")
+)
+
+// authorize authenticates the caller with a and lets the request through
+// only when the caller's role includes required. The principal is put on
+// the request context for the handlers below. A nil a lets everything
+// through.
+func authorize(a auth.Authenticator, required auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			return
+		}
+
+		p, err := a.Authenticate(c.Request)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="offers"`)
+
+			if errors.Is(err, auth.ErrNoCredentials) {
+				abortWithError(c, http.StatusUnauthorized, errUnauthenticated)
+			} else {
+				abortWithError(c, http.StatusUnauthorized, errBadCredentials)
+			}
+
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+
+		if !p.Role.Allows(required) {
+			abortWithError(c, http.StatusForbidden, errForbidden)
+		}
+	}
+}
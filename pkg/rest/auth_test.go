@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuthorization(t *testing.T) {
+	keys, err := auth.ParseAPIKeys([]string{
+		"vic:viewer:viewer-key",
+		"rita:recruiter:recruiter-key",
+		"ada:admin:admin-key",
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := SetupRouteHandlers(&RouteHandlers{
+		CreateOffer:  &testCreateOffer{},
+		UpdateOffer:  &testUpdateOffer{},
+		GetOffer:     &testGetOffer{},
+		GetAllOffers: &testGetAllOffers{},
+		DeleteOffer:  &testDeleteOffer{},
+		Auth:         keys,
+	}, zap.NewNop().Sugar())
+
+	const offerPath = "/offers/eca51142-3bf0-4766-baf7-2a168c964024"
+
+	createBody, err := json.Marshal(&api.JobOfferRequest{
+		Company:        "TEST",
+		Email:          "test@hr-test.com",
+		ExpirationDate: "2030-03-01T14:30:00Z",
+		LinkToOffer:    "http://test.com/carriers",
+		Details:        "We are looking for a Ninja Golang developer",
+		Salary:         18000,
+		ContactPhone:   "+38978653534",
+	})
+	require.NoError(t, err)
+
+	updateBody, err := json.Marshal(&api.UpdateJobOfferRequest{
+		Company:      "TEST",
+		Email:        "test@hr-test.com",
+		LinkToOffer:  "http://test.com/carriers",
+		Salary:       18000,
+		ContactPhone: "+38978653534",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		method         string
+		path           string
+		body           []byte
+		apiKey         string
+		expectedStatus int
+	}{
+		{"GET", "/ping", nil, "", http.StatusOK},
+		{"GET", "/healthz", nil, "", http.StatusOK},
+		{"GET", "/offers", nil, "", http.StatusUnauthorized},
+		{"GET", "/offers", nil, "wrong-key", http.StatusUnauthorized},
+		{"GET", "/offers", nil, "viewer-key", http.StatusOK},
+		{"GET", offerPath, nil, "viewer-key", http.StatusOK},
+		{"POST", "/offers", createBody, "viewer-key", http.StatusForbidden},
+		{"POST", "/offers", createBody, "recruiter-key", http.StatusCreated},
+		{"PUT", offerPath, updateBody, "viewer-key", http.StatusForbidden},
+		{"PUT", offerPath, updateBody, "recruiter-key", http.StatusOK},
+		{"DELETE", offerPath, nil, "", http.StatusUnauthorized},
+		{"DELETE", offerPath, nil, "viewer-key", http.StatusForbidden},
+		{"DELETE", offerPath, nil, "recruiter-key", http.StatusOK},
+		{"DELETE", offerPath, nil, "admin-key", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s as %q", test.method, test.path, test.apiKey), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, test.path, bytes.NewReader(test.body))
+			if test.apiKey != "" {
+				req.Header.Set(auth.APIKeyHeader, test.apiKey)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+
+			switch test.expectedStatus {
+			case http.StatusUnauthorized:
+				assert.Equal(t, `Bearer realm="offers"`, w.Header().Get("WWW-Authenticate"))
+				assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+			case http.StatusForbidden:
+				assert.Contains(t, w.Body.String(), `"code":"forbidden"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// batchRoute guards the "/offers:batch" routes. gin takes ":batch" for a
+// path parameter, so without this they would match "/offersX" as well.
+func batchRoute() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Param("batch") != ":batch" {
+			abortWithError(c, http.StatusNotFound, errRouteNotFound)
+		}
+	}
+}
+
+// batchError fails an all-or-nothing batch on behalf of the items that
+// could not be stored, keyed by their position in the request.
+type batchError struct {
+	items map[string]string
+}
+
+func (e *batchError) Error() string { return storage.ErrBatchFailed.Error() }
+
+func createBatch(b offers.BatchCreateOffers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		var request api.BatchCreateRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := request.Validate(); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		results := make([]api.BatchItemResult, len(request.Offers))
+		invalid := validation.Errors{}
+
+		// Only the valid offers reach the storage, positions tells where
+		// each of them sits in the request.
+		reqs := make([]*api.JobOfferRequest, 0, len(request.Offers))
+		positions := make([]int, 0, len(request.Offers))
+
+		for i := range request.Offers {
+			if err := request.Offers[i].Validate(); err != nil {
+				results[i] = failedItem(http.StatusBadRequest, err)
+				addItemErrors(invalid, fmt.Sprintf("offers[%d]", i), err)
+
+				continue
+			}
+
+			reqs = append(reqs, &request.Offers[i])
+			positions = append(positions, i)
+		}
+
+		if len(invalid) > 0 && !request.BestEffort() {
+			abortWithError(c, http.StatusBadRequest, invalid)
+
+			return
+		}
+
+		if len(reqs) > 0 {
+			stored, err := b.CreateBatch(c.Request.Context(), reqs, request.BestEffort())
+			if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+				abortWithError(c, storageErrorStatus(err), err)
+
+				return
+			}
+
+			for j, r := range stored {
+				i := positions[j]
+
+				switch {
+				case r.Err != nil:
+					results[i] = failedItem(storageErrorStatus(r.Err), r.Err)
+				case r.Offer != nil:
+					results[i] = api.BatchItemResult{Status: http.StatusCreated, ID: r.Offer.ID, Offer: r.Offer}
+				}
+			}
+
+			if err != nil {
+				abortWithBatchError(c, "offers", results)
+
+				return
+			}
+		}
+
+		respondBatch(c, http.StatusCreated, results)
+	}
+}
+
+// patchBatch fails the items without a version with 428 when versions are
+// required, as requireIfMatch does for a single offer.
+func patchBatch(b offers.BatchPatchOffers, requireVersion bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		var request api.BatchPatchRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := request.Validate(); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		results := make([]api.BatchItemResult, len(request.Offers))
+		invalid := validation.Errors{}
+
+		unversioned := false
+
+		reqs := make([]*api.BatchPatchItem, 0, len(request.Offers))
+		positions := make([]int, 0, len(request.Offers))
+
+		for i := range request.Offers {
+			if err := request.Offers[i].Validate(); err != nil {
+				results[i] = failedItem(http.StatusBadRequest, err)
+				results[i].ID = request.Offers[i].ID
+				addItemErrors(invalid, fmt.Sprintf("offers[%d]", i), err)
+
+				continue
+			}
+
+			if requireVersion && request.Offers[i].Version == 0 {
+				results[i] = failedItem(http.StatusPreconditionRequired, errVersionRequired)
+				results[i].ID = request.Offers[i].ID
+				unversioned = true
+
+				continue
+			}
+
+			reqs = append(reqs, &request.Offers[i])
+			positions = append(positions, i)
+		}
+
+		if !request.BestEffort() {
+			switch {
+			case len(invalid) > 0:
+				abortWithError(c, http.StatusBadRequest, invalid)
+
+				return
+			case unversioned:
+				abortWithBatchError(c, "offers", results)
+
+				return
+			}
+		}
+
+		if len(reqs) > 0 {
+			patched, err := b.PatchBatch(c.Request.Context(), reqs, request.BestEffort())
+			if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+				abortWithError(c, storageErrorStatus(err), err)
+
+				return
+			}
+
+			for j, r := range patched {
+				i := positions[j]
+
+				switch {
+				case r.Err != nil:
+					results[i] = failedItem(storageErrorStatus(r.Err), r.Err)
+				case r.Offer != nil:
+					results[i] = api.BatchItemResult{Status: http.StatusOK, Offer: r.Offer}
+				}
+
+				results[i].ID = reqs[j].ID
+			}
+
+			if err != nil {
+				abortWithBatchError(c, "offers", results)
+
+				return
+			}
+		}
+
+		respondBatch(c, http.StatusOK, results)
+	}
+}
+
+// deleteBatch requires versions like patchBatch does.
+func deleteBatch(b offers.BatchDeleteOffers, requireVersion bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		var request api.BatchDeleteRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if err := request.Validate(); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		results := make([]api.BatchItemResult, len(request.IDs))
+		invalid := validation.Errors{}
+
+		unversioned := false
+
+		reqs := make([]*api.BatchDeleteItem, 0, len(request.IDs))
+		positions := make([]int, 0, len(request.IDs))
+
+		for i := range request.IDs {
+			if err := request.IDs[i].Validate(); err != nil {
+				results[i] = failedItem(http.StatusBadRequest, err)
+				results[i].ID = request.IDs[i].ID
+				addItemErrors(invalid, fmt.Sprintf("ids[%d]", i), err)
+
+				continue
+			}
+
+			if requireVersion && request.IDs[i].Version == 0 {
+				results[i] = failedItem(http.StatusPreconditionRequired, errVersionRequired)
+				results[i].ID = request.IDs[i].ID
+				unversioned = true
+
+				continue
+			}
+
+			reqs = append(reqs, &request.IDs[i])
+			positions = append(positions, i)
+		}
+
+		if !request.BestEffort() {
+			switch {
+			case len(invalid) > 0:
+				abortWithError(c, http.StatusBadRequest, invalid)
+
+				return
+			case unversioned:
+				abortWithBatchError(c, "ids", results)
+
+				return
+			}
+		}
+
+		if len(reqs) > 0 {
+			deleted, err := b.DeleteBatch(c.Request.Context(), reqs, request.BestEffort())
+			if err != nil && !errors.Is(err, storage.ErrBatchFailed) {
+				abortWithError(c, storageErrorStatus(err), err)
+
+				return
+			}
+
+			for j, r := range deleted {
+				i := positions[j]
+
+				if r.Err != nil {
+					results[i] = failedItem(storageErrorStatus(r.Err), r.Err)
+				} else {
+					results[i] = api.BatchItemResult{Status: http.StatusOK}
+				}
+
+				results[i].ID = reqs[j].ID
+			}
+
+			if err != nil {
+				abortWithBatchError(c, "ids", results)
+
+				return
+			}
+		}
+
+		respondBatch(c, http.StatusOK, results)
+	}
+}
+
+// failedItem reports an item with the error body it would have had as a
+// request of its own.
+func failedItem(status int, err error) api.BatchItemResult {
+	return api.BatchItemResult{
+		Status: status,
+		Error:  newErrorResponse(status, err, ""),
+	}
+}
+
+// addItemErrors files the validation errors of the item at key into errs.
+func addItemErrors(errs validation.Errors, key string, err error) {
+	var fields validation.Errors
+	if !errors.As(err, &fields) {
+		errs[key] = err
+
+		return
+	}
+
+	for field, e := range fields {
+		if e != nil {
+			errs[key+"."+field] = e
+		}
+	}
+}
+
+// abortWithBatchError fails an all-or-nothing batch with the status of its
+// first failed item, listing every failed item in the details.
+func abortWithBatchError(c *gin.Context, key string, results []api.BatchItemResult) {
+	status := http.StatusInternalServerError
+	err := &batchError{items: make(map[string]string)}
+
+	for i := len(results) - 1; i >= 0; i-- {
+		if r := results[i]; r.Error != nil {
+			status = r.Status
+			err.items[fmt.Sprintf("%s[%d]", key, i)] = r.Error.Message
+		}
+	}
+
+	abortWithError(c, status, err)
+}
+
+// respondBatch answers with status when every item succeeded and with 207
+// Multi-Status otherwise.
+func respondBatch(c *gin.Context, status int, results []api.BatchItemResult) {
+	resp := &api.BatchResponse{Results: results}
+
+	for _, r := range results {
+		if r.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+	}
+
+	if resp.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	c.JSON(status, resp)
+}
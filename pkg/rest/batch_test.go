@@ -0,0 +1,417 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const missingOfferID = "00000000-0000-0000-0000-000000000000"
+
+// testBatchOffers fails offers of the company "TAKEN" with a conflict and
+// changes to missingOfferID with not found, rolling back like the storage
+// does.
+type testBatchOffers struct {
+	batchCalled int
+	batchSize   int
+}
+
+func (b *testBatchOffers) CreateBatch(_ context.Context, reqs []*api.JobOfferRequest, bestEffort bool) ([]offers.BatchResult, error) {
+	b.batchCalled++
+	b.batchSize = len(reqs)
+
+	results := make([]offers.BatchResult, len(reqs))
+	for i, req := range reqs {
+		if req.Company == "TAKEN" {
+			results[i].Err = storage.ErrConflict
+		} else {
+			results[i].Offer = &api.JobOfferResponse{ID: fmt.Sprintf("offer-%d", i), Company: req.Company, Version: 1}
+		}
+	}
+
+	return b.outcome(results, bestEffort)
+}
+
+func (b *testBatchOffers) PatchBatch(_ context.Context, reqs []*api.BatchPatchItem, bestEffort bool) ([]offers.BatchResult, error) {
+	b.batchCalled++
+	b.batchSize = len(reqs)
+
+	results := make([]offers.BatchResult, len(reqs))
+	for i, req := range reqs {
+		if req.ID == missingOfferID {
+			results[i].Err = storage.ErrNotFound
+		} else {
+			results[i].Offer = &api.JobOfferResponse{ID: req.ID, Salary: req.Patch.Salary.Float64, Version: 2}
+		}
+	}
+
+	return b.outcome(results, bestEffort)
+}
+
+func (b *testBatchOffers) DeleteBatch(_ context.Context, reqs []*api.BatchDeleteItem, bestEffort bool) ([]offers.BatchResult, error) {
+	b.batchCalled++
+	b.batchSize = len(reqs)
+
+	results := make([]offers.BatchResult, len(reqs))
+	for i, req := range reqs {
+		if req.ID == missingOfferID {
+			results[i].Err = storage.ErrNotFound
+		}
+	}
+
+	return b.outcome(results, bestEffort)
+}
+
+func (b *testBatchOffers) outcome(results []offers.BatchResult, bestEffort bool) ([]offers.BatchResult, error) {
+	if bestEffort {
+		return results, nil
+	}
+
+	for i := range results {
+		if results[i].Err != nil {
+			for j := range results {
+				results[j].Offer = nil
+			}
+
+			return results, storage.ErrBatchFailed
+		}
+	}
+
+	return results, nil
+}
+
+func batchOffer(company string) string {
+	return fmt.Sprintf(`{
+		"company": %q,
+		"email": "test@hr-test.com",
+		"details": "Some details",
+		"link": "http://test.com/carriers",
+		"salary": 18000,
+		"phone": "+38978653534"
+	}`, company)
+}
+
+func TestBatchCreate(t *testing.T) {
+	tests := []struct {
+		body             string
+		expectedStatus   int
+		expectedCalls    int
+		expectedSize     int
+		expectedStatuses []int
+		expectedDetails  []string
+	}{
+		{
+			`{"offers": [` + batchOffer("ACME") + `,` + batchOffer("TEST") + `]}`,
+			http.StatusCreated, 1, 2, []int{201, 201}, nil,
+		},
+		{
+			`{"offers": [` + batchOffer("ACME") + `,` + batchOffer("TAKEN") + `]}`,
+			http.StatusConflict, 1, 2, nil, []string{"offers[1]"},
+		},
+		{
+			`{"mode": "best_effort", "offers": [` + batchOffer("ACME") + `,` + batchOffer("TAKEN") + `]}`,
+			http.StatusMultiStatus, 1, 2, []int{201, 409}, nil,
+		},
+		{
+			`{"offers": [` + batchOffer("ACME") + `,` + batchOffer("") + `]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"offers[1].company"},
+		},
+		{
+			`{"mode": "best_effort", "offers": [` + batchOffer("") + `,` + batchOffer("ACME") + `]}`,
+			http.StatusMultiStatus, 1, 1, []int{400, 201}, nil,
+		},
+		{
+			`{"mode": "best_effort", "offers": [` + batchOffer("") + `]}`,
+			http.StatusMultiStatus, 0, 0, []int{400}, nil,
+		},
+		{
+			`{"offers": []}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"offers"},
+		},
+		{
+			`{"mode": "sometimes", "offers": [` + batchOffer("ACME") + `]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"mode"},
+		},
+		{
+			`{"offers": [`,
+			http.StatusBadRequest, 0, 0, nil, nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			b := &testBatchOffers{}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{BatchCreateOffers: b}, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/offers:batch", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedCalls, b.batchCalled)
+			assert.Equal(t, test.expectedSize, b.batchSize)
+			assertBatchResponse(t, w, test.expectedStatuses, test.expectedDetails)
+		})
+	}
+}
+
+func TestBatchDelete(t *testing.T) {
+	const offerID = "eca51142-3bf0-4766-baf7-2a168c964024"
+
+	tests := []struct {
+		body             string
+		expectedStatus   int
+		expectedCalls    int
+		expectedSize     int
+		expectedStatuses []int
+		expectedDetails  []string
+	}{
+		{
+			`{"ids": ["` + offerID + `"]}`,
+			http.StatusOK, 1, 1, []int{200}, nil,
+		},
+		{
+			`{"ids": ["` + offerID + `", "` + missingOfferID + `"]}`,
+			http.StatusNotFound, 1, 2, nil, []string{"ids[1]"},
+		},
+		{
+			`{"mode": "best_effort", "ids": ["` + missingOfferID + `", "` + offerID + `"]}`,
+			http.StatusMultiStatus, 1, 2, []int{404, 200}, nil,
+		},
+		{
+			`{"ids": ["` + offerID + `", "1234"]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"ids[1]"},
+		},
+		{
+			`{"mode": "best_effort", "ids": ["1234", "` + offerID + `"]}`,
+			http.StatusMultiStatus, 1, 1, []int{400, 200}, nil,
+		},
+		{
+			`{"ids": [{"id": "` + offerID + `", "version": 3}, "` + offerID + `"]}`,
+			http.StatusOK, 1, 2, []int{200, 200}, nil,
+		},
+		{
+			`{"ids": [{"id": "` + offerID + `", "version": -1}]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"ids[0].version"},
+		},
+		{
+			`{}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"ids"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			b := &testBatchOffers{}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{BatchDeleteOffers: b}, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/offers:batch", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedCalls, b.batchCalled)
+			assert.Equal(t, test.expectedSize, b.batchSize)
+			assertBatchResponse(t, w, test.expectedStatuses, test.expectedDetails)
+		})
+	}
+}
+
+func TestBatchPatch(t *testing.T) {
+	const offerID = "eca51142-3bf0-4766-baf7-2a168c964024"
+
+	patch := func(id, patch string) string {
+		return fmt.Sprintf(`{"id": %q, "patch": %s}`, id, patch)
+	}
+
+	tests := []struct {
+		body             string
+		expectedStatus   int
+		expectedCalls    int
+		expectedSize     int
+		expectedStatuses []int
+		expectedDetails  []string
+	}{
+		{
+			`{"offers": [` + patch(offerID, `{"salary": 9000}`) + `]}`,
+			http.StatusOK, 1, 1, []int{200}, nil,
+		},
+		{
+			`{"offers": [` + patch(offerID, `{"salary": 9000}`) + `,` + patch(missingOfferID, `{}`) + `]}`,
+			http.StatusNotFound, 1, 2, nil, []string{"offers[1]"},
+		},
+		{
+			`{"mode": "best_effort", "offers": [` + patch(missingOfferID, `{}`) + `,` + patch(offerID, `{"details": null}`) + `]}`,
+			http.StatusMultiStatus, 1, 2, []int{404, 200}, nil,
+		},
+		{
+			`{"offers": [` + patch("1234", `{}`) + `,` + patch(offerID, `{"company": null}`) + `]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"offers[0].id", "offers[1].patch.company"},
+		},
+		{
+			`{"mode": "best_effort", "offers": [` + patch(offerID, `{"expiration_date": ""}`) + `,` + patch(offerID, `{}`) + `]}`,
+			http.StatusMultiStatus, 1, 1, []int{400, 200}, nil,
+		},
+		{
+			`{"offers": [{"id": "` + offerID + `", "version": -1, "patch": {}}]}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"offers[0].version"},
+		},
+		{
+			`{"offers": []}`,
+			http.StatusBadRequest, 0, 0, nil, []string{"offers"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			b := &testBatchOffers{}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{BatchPatchOffers: b}, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/offers:batch", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedCalls, b.batchCalled)
+			assert.Equal(t, test.expectedSize, b.batchSize)
+			assertBatchResponse(t, w, test.expectedStatuses, test.expectedDetails)
+		})
+	}
+}
+
+func TestBatchRequireVersion(t *testing.T) {
+	const offerID = "eca51142-3bf0-4766-baf7-2a168c964024"
+
+	tests := []struct {
+		method           string
+		body             string
+		expectedStatus   int
+		expectedCalls    int
+		expectedSize     int
+		expectedStatuses []int
+		expectedDetails  []string
+	}{
+		{
+			"PATCH", `{"offers": [{"id": "` + offerID + `", "version": 2, "patch": {}}]}`,
+			http.StatusOK, 1, 1, []int{200}, nil,
+		},
+		{
+			"PATCH", `{"offers": [{"id": "` + offerID + `", "version": 2, "patch": {}}, {"id": "` + offerID + `", "patch": {}}]}`,
+			http.StatusPreconditionRequired, 0, 0, nil, []string{"offers[1]"},
+		},
+		{
+			"PATCH", `{"mode": "best_effort", "offers": [{"id": "` + offerID + `", "patch": {}}, {"id": "` + offerID + `", "version": 2, "patch": {}}]}`,
+			http.StatusMultiStatus, 1, 1, []int{428, 200}, nil,
+		},
+		{
+			"DELETE", `{"ids": [{"id": "` + offerID + `", "version": 2}]}`,
+			http.StatusOK, 1, 1, []int{200}, nil,
+		},
+		{
+			"DELETE", `{"ids": ["` + offerID + `"]}`,
+			http.StatusPreconditionRequired, 0, 0, nil, []string{"ids[0]"},
+		},
+		{
+			"DELETE", `{"mode": "best_effort", "ids": [{"id": "` + offerID + `", "version": 2}, "` + offerID + `"]}`,
+			http.StatusMultiStatus, 1, 1, []int{200, 428}, nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			b := &testBatchOffers{}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{
+				BatchPatchOffers:  b,
+				BatchDeleteOffers: b,
+				RequireIfMatch:    true,
+			}, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "/offers:batch", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedCalls, b.batchCalled)
+			assert.Equal(t, test.expectedSize, b.batchSize)
+			assertBatchResponse(t, w, test.expectedStatuses, test.expectedDetails)
+		})
+	}
+}
+
+func assertBatchResponse(t *testing.T, w *httptest.ResponseRecorder, statuses []int, details []string) {
+	t.Helper()
+
+	if statuses != nil {
+		var resp api.BatchResponse
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		var got []int
+		for _, r := range resp.Results {
+			got = append(got, r.Status)
+		}
+
+		assert.Equal(t, statuses, got)
+		assert.Equal(t, len(statuses), resp.Succeeded+resp.Failed)
+	}
+
+	if details != nil {
+		var resp api.ErrorResponse
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		var got []string
+		for field := range resp.Details {
+			got = append(got, field)
+		}
+
+		assert.ElementsMatch(t, details, got)
+	}
+}
+
+func TestBatchRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := SetupRouteHandlers(&RouteHandlers{
+		CreateOffer:       &testCreateOffer{},
+		BatchCreateOffers: &testBatchOffers{},
+		BatchPatchOffers:  &testBatchOffers{},
+		BatchDeleteOffers: &testBatchOffers{},
+	}, zap.NewNop().Sugar())
+
+	// gin takes ":batch" for a parameter, so anything following "/offers"
+	// would reach the batch handlers without batchRoute.
+	for _, path := range []string{"/offersX", "/offersbatch", "/offers:bulk", "/offers:batch/x"} {
+		for _, method := range []string{"POST", "PATCH", "DELETE"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(`{}`)))
+
+			assert.Equal(t, http.StatusNotFound, w.Code, method+" "+path)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	errRouteNotFound = errors.New("route not found")
+	errNotInteger    = errors.New("must be an integer")
+	errNotNumber     = errors.New("must be a number")
+	errNotTimestamp  = errors.New("must be an RFC 3339 timestamp")
+	errNotBoolean    = errors.New("must be a boolean")
+	errNoCaller      = errors.New("requires an authenticated caller")
+)
+
+// abortWithError records err on the context and stops the handler chain
+// without writing the response, leaving rendering to errorMiddleware.
+func abortWithError(c *gin.Context, status int, err error) {
+	c.Status(status)
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func errorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest {
+			status = http.StatusInternalServerError
+		}
+
+		c.JSON(status, newErrorResponse(status, c.Errors.Last().Err, requestID(c)))
+	}
+}
+
+func recoveryHandler(c *gin.Context, _ interface{}) {
+	c.AbortWithStatusJSON(
+		http.StatusInternalServerError,
+		newErrorResponse(http.StatusInternalServerError, nil, requestID(c)),
+	)
+}
+
+func newErrorResponse(status int, err error, requestID string) *api.ErrorResponse {
+	resp := &api.ErrorResponse{
+		Code:      errorCode(status),
+		Message:   http.StatusText(status),
+		RequestID: requestID,
+	}
+
+	var errs validation.Errors
+	var batchErr *batchError
+	switch {
+	case errors.As(err, &batchErr):
+		resp.Message = batchErr.Error()
+		resp.Details = batchErr.items
+	case errors.As(err, &errs):
+		resp.Code = api.CodeValidationFailed
+		resp.Message = "request validation failed"
+		resp.Details = make(map[string]string, len(errs))
+		for field, e := range errs {
+			if e != nil {
+				resp.Details[field] = e.Error()
+			}
+		}
+	case err != nil && status < http.StatusInternalServerError:
+		resp.Message = err.Error()
+	}
+
+	return resp
+}
+
+func errorCode(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return api.CodeUnauthorized
+	case http.StatusForbidden:
+		return api.CodeForbidden
+	case http.StatusNotFound:
+		return api.CodeNotFound
+	case http.StatusConflict:
+		return api.CodeConflict
+	case http.StatusPreconditionFailed:
+		return api.CodePreconditionFailed
+	case http.StatusPreconditionRequired:
+		return api.CodePreconditionRequired
+	case http.StatusUnprocessableEntity:
+		return api.CodeValidationFailed
+	}
+
+	if status >= http.StatusInternalServerError {
+		return api.CodeInternal
+	}
+
+	return api.CodeBadRequest
+}
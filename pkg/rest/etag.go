@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errIfMatchRequired = errors.New("If-Match header is required")
+	errVersionRequired = errors.New("version is required")
+	errIfMatchList     = errors.New("If-Match must hold a single entity tag")
+	errETagMismatch    = errors.New("offer has been modified in the meantime")
+)
+
+// etag renders the version of an offer as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+func setETag(c *gin.Context, resp *api.JobOfferResponse) {
+	if resp != nil && resp.Version > 0 {
+		c.Header("ETag", etag(resp.Version))
+	}
+}
+
+// requireIfMatch rejects unconditional writes with 428 Precondition
+// Required when required is set.
+func requireIfMatch(required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if required && c.GetHeader("If-Match") == "" {
+			abortWithError(c, http.StatusPreconditionRequired, errIfMatchRequired)
+		}
+	}
+}
+
+// ifMatchVersion returns the offer version the If-Match header asks for,
+// or 0 when the write is unconditional because the header is missing or
+// "*". Weak or malformed tags can never match and yield 412 directly. It
+// reports false when it has already aborted the request.
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	if strings.Contains(header, ",") {
+		abortWithError(c, http.StatusBadRequest, errIfMatchList)
+
+		return 0, false
+	}
+
+	version, ok := parseETag(header)
+	if !ok {
+		abortWithError(c, http.StatusPreconditionFailed, errETagMismatch)
+
+		return 0, false
+	}
+
+	return version, true
+}
+
+// notModified reports whether any tag in If-None-Match matches version,
+// using the weak comparison RFC 7232 prescribes for it.
+func notModified(c *gin.Context, version int64) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+		if tag == "*" {
+			return true
+		}
+
+		if v, ok := parseETag(tag); ok && v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseETag(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
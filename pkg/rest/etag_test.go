@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// testVersionedOffer mimics the version check of the storage layer for a
+// single offer.
+type testVersionedOffer struct {
+	version int64
+}
+
+func (o *testVersionedOffer) check(version int64) error {
+	if version != 0 && version != o.version {
+		return offers.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+func (o *testVersionedOffer) Get(context.Context, string) (*api.JobOfferResponse, error) {
+	return &api.JobOfferResponse{Version: o.version}, nil
+}
+
+func (o *testVersionedOffer) Update(_ context.Context, _ string, version int64, _ *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error) {
+	if err := o.check(version); err != nil {
+		return nil, err
+	}
+
+	o.version++
+
+	return &api.JobOfferResponse{Version: o.version}, nil
+}
+
+func (o *testVersionedOffer) Patch(_ context.Context, _ string, version int64, _ *api.PatchJobOfferRequest) (*api.JobOfferResponse, error) {
+	if err := o.check(version); err != nil {
+		return nil, err
+	}
+
+	o.version++
+
+	return &api.JobOfferResponse{Version: o.version}, nil
+}
+
+func (o *testVersionedOffer) DeleteByID(_ context.Context, _ string, version int64) error {
+	return o.check(version)
+}
+
+func TestETags(t *testing.T) {
+	const path = "/offers/eca51142-3bf0-4766-baf7-2a168c964024"
+
+	const updateBody = `{
+		"company": "TEST",
+		"email": "test@hr-test.com",
+		"link": "http://test.com/carriers",
+		"salary": 18000,
+		"phone": "+38978653534"
+	}`
+
+	tests := []struct {
+		method         string
+		header         string
+		value          string
+		requireIfMatch bool
+		expectedStatus int
+		expectedETag   string
+	}{
+		{"GET", "", "", false, http.StatusOK, `"3"`},
+		{"GET", "If-None-Match", `"3"`, false, http.StatusNotModified, `"3"`},
+		{"GET", "If-None-Match", `W/"3"`, false, http.StatusNotModified, `"3"`},
+		{"GET", "If-None-Match", `"1", "3"`, false, http.StatusNotModified, `"3"`},
+		{"GET", "If-None-Match", `*`, false, http.StatusNotModified, `"3"`},
+		{"GET", "If-None-Match", `"2"`, false, http.StatusOK, `"3"`},
+		{"PUT", "", "", false, http.StatusOK, `"4"`},
+		{"PUT", "", "", true, http.StatusPreconditionRequired, ""},
+		{"PUT", "If-Match", `"3"`, true, http.StatusOK, `"4"`},
+		{"PUT", "If-Match", `*`, true, http.StatusOK, `"4"`},
+		{"PUT", "If-Match", `"2"`, false, http.StatusPreconditionFailed, ""},
+		{"PUT", "If-Match", `W/"3"`, false, http.StatusPreconditionFailed, ""},
+		{"PUT", "If-Match", `"2", "3"`, false, http.StatusBadRequest, ""},
+		{"PATCH", "If-Match", `"3"`, false, http.StatusOK, `"4"`},
+		{"PATCH", "If-Match", `"2"`, false, http.StatusPreconditionFailed, ""},
+		{"PATCH", "", "", true, http.StatusPreconditionRequired, ""},
+		{"DELETE", "If-Match", `"3"`, false, http.StatusOK, ""},
+		{"DELETE", "If-Match", `"2"`, false, http.StatusPreconditionFailed, ""},
+		{"DELETE", "", "", true, http.StatusPreconditionRequired, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			offer := &testVersionedOffer{version: 3}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{
+				GetOffer:       offer,
+				UpdateOffer:    offer,
+				PatchOffer:     offer,
+				DeleteOffer:    offer,
+				RequireIfMatch: test.requireIfMatch,
+			}, zap.NewNop().Sugar())
+
+			body := ""
+			switch test.method {
+			case "PUT":
+				body = updateBody
+			case "PATCH":
+				body = `{"salary": 9000}`
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.header != "" {
+				req.Header.Set(test.header, test.value)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedETag, w.Header().Get("ETag"))
+
+			switch test.expectedStatus {
+			case http.StatusNotModified:
+				assert.Empty(t, w.Body.String())
+			case http.StatusPreconditionFailed:
+				assert.Contains(t, w.Body.String(), `"code":"precondition_failed"`)
+			case http.StatusPreconditionRequired:
+				assert.Contains(t, w.Body.String(), `"code":"precondition_required"`)
+			}
+		})
+	}
+}
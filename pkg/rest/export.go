@@ -0,0 +1,99 @@
+package rest
+
+import (
+	"bufio"
+	"mime"
+	"net/http"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offerio"
+	"example.com/playground/pkg/offers"
+
+	"github.com/gin-gonic/gin"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// exportFlushRows is how many offers are buffered before they are sent on.
+const exportFlushRows = 100
+
+// export streams every offer matching the listing filters as a file
+// download. Once the first bytes are out the status can't change anymore,
+// so a failure halfway only ends the file early and is logged.
+func export(ex offers.ExportOffers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, err := offerio.ParseFormat(c.DefaultQuery("format", string(offerio.FormatJSONL)))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, validation.Errors{"format": err})
+
+			return
+		}
+
+		filter, err := parseOffersFilter(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		buf := bufio.NewWriter(c.Writer)
+
+		w, err := offerio.NewWriter(buf, format)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		c.Header("Content-Type", offerio.ContentType(format))
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": exportFilename(format, time.Now()),
+		}))
+		c.Status(http.StatusOK)
+
+		var rows int
+
+		err = ex.Export(c.Request.Context(), filter, func(o *api.JobOfferResponse) error {
+			if err := w.Write(o); err != nil {
+				return err
+			}
+
+			if rows++; rows%exportFlushRows == 0 {
+				if err := w.Flush(); err != nil {
+					return err
+				}
+
+				if err := buf.Flush(); err != nil {
+					return err
+				}
+
+				c.Writer.Flush()
+			}
+
+			return nil
+		})
+		if err == nil {
+			if err = w.Flush(); err == nil {
+				err = buf.Flush()
+			}
+		}
+
+		if err != nil {
+			if !c.Writer.Written() {
+				c.Writer.Header().Del("Content-Type")
+				c.Writer.Header().Del("Content-Disposition")
+				abortWithError(c, storageErrorStatus(err), err)
+
+				return
+			}
+
+			_ = c.Error(err)
+			c.Abort()
+		}
+	}
+}
+
+// exportFilename names an export after the time it was taken.
+func exportFilename(f offerio.Format, now time.Time) string {
+	return "offers-" + now.UTC().Format("20060102T150405Z") + "." + string(f)
+}
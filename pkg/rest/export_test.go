@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testExportOffers struct {
+	exportCalled int
+	filter       *api.OffersFilter
+	offers       int
+	err          error
+}
+
+func (e *testExportOffers) Export(_ context.Context, filter *api.OffersFilter, fn func(*api.JobOfferResponse) error) error {
+	e.exportCalled++
+	e.filter = filter
+
+	for i := 0; i < e.offers; i++ {
+		if err := fn(&api.JobOfferResponse{ID: fmt.Sprintf("offer-%d", i), Company: "ACME", Version: 1}); err != nil {
+			return err
+		}
+	}
+
+	return e.err
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		query               string
+		offers              int
+		exportErr           error
+		expectedCalls       int
+		expectedStatus      int
+		expectedContentType string
+		expectedLines       int
+	}{
+		{"", 2, nil, 1, http.StatusOK, "application/x-ndjson", 2},
+		{"?format=jsonl&company=ACME", 0, nil, 1, http.StatusOK, "application/x-ndjson", 0},
+		{"?format=csv", 2, nil, 1, http.StatusOK, "text/csv; charset=utf-8", 3},
+		{"?format=csv", 0, nil, 1, http.StatusOK, "text/csv; charset=utf-8", 1},
+		{"?format=parquet", 2, nil, 0, http.StatusBadRequest, "application/json; charset=utf-8", 0},
+		{"?min_salary=cheap", 2, nil, 0, http.StatusBadRequest, "application/json; charset=utf-8", 0},
+		{"?mine=true", 2, nil, 0, http.StatusBadRequest, "application/json; charset=utf-8", 0},
+		{"", 0, errors.New("oops..something went wrong"), 1, http.StatusInternalServerError, "application/json; charset=utf-8", 0},
+		// Failures once the first rows are out only cut the file short.
+		{"", 250, errors.New("oops..something went wrong"), 1, http.StatusOK, "application/x-ndjson", 250},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			e := &testExportOffers{offers: test.offers, err: test.exportErr}
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{ExportOffers: e}, zap.NewNop().Sugar())
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/offers/export"+test.query, nil))
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Equal(t, test.expectedCalls, e.exportCalled)
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			if w.Code == http.StatusOK {
+				lines := strings.Count(w.Body.String(), "\n")
+				if test.exportErr == nil {
+					assert.Equal(t, test.expectedLines, lines)
+				} else {
+					assert.Less(t, lines, test.expectedLines)
+				}
+
+				assert.Regexp(t, `^attachment; filename=offers-\d{8}T\d{6}Z\.(csv|jsonl)$`, w.Header().Get("Content-Disposition"))
+			} else {
+				assert.Empty(t, w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestExportFilter(t *testing.T) {
+	e := &testExportOffers{}
+
+	gin.SetMode(gin.TestMode)
+	r := SetupRouteHandlers(&RouteHandlers{ExportOffers: e}, zap.NewNop().Sugar())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/offers/export?company=ACME&q=golang&include_expired=true", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &api.OffersFilter{Company: "ACME", Query: "golang", IncludeExpired: true}, e.filter)
+}
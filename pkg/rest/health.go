@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// liveness only reports that the process is serving requests. Dependencies
+// are deliberately left to readiness so that a database outage takes
+// replicas out of rotation instead of getting them restarted.
+func liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, &api.HealthResponse{Status: api.HealthStatusOK})
+	}
+}
+
+func readiness(checks map[string]storage.HealthCheck, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		resp := &api.HealthResponse{
+			Status: api.HealthStatusOK,
+			Checks: make(map[string]api.CheckResult, len(checks)),
+		}
+
+		for name, check := range checks {
+			details, err := check.Check(ctx)
+
+			result := api.CheckResult{Status: api.CheckStatusUp, Details: details}
+			if err != nil {
+				result.Status = api.CheckStatusDown
+				result.Error = err.Error()
+				resp.Status = api.HealthStatusUnavailable
+			}
+
+			resp.Checks[name] = result
+		}
+
+		if resp.Status != api.HealthStatusOK {
+			c.JSON(http.StatusServiceUnavailable, resp)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
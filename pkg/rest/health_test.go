@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testHealthCheck struct {
+	details interface{}
+	err     error
+	block   bool
+}
+
+func (h *testHealthCheck) Check(ctx context.Context) (interface{}, error) {
+	if h.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return h.details, h.err
+}
+
+func TestLiveness(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest("GET", "/healthz", nil)
+
+	liveness()(ctx)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, w.Body.String())
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         map[string]storage.HealthCheck
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			"all dependencies up",
+			map[string]storage.HealthCheck{
+				"database":   &testHealthCheck{details: &api.PoolStats{OpenConnections: 2, Idle: 2, WaitDuration: "0s"}},
+				"migrations": &testHealthCheck{details: &api.MigrationStats{Version: 2}},
+			},
+			http.StatusOK,
+			`{
+				"status": "ok",
+				"checks": {
+					"database": {"status": "up", "details": {"max_open_connections": 0, "open_connections": 2, "in_use": 0, "idle": 2, "wait_count": 0, "wait_duration": "0s"}},
+					"migrations": {"status": "up", "details": {"version": 2, "pending": 0}}
+				}
+			}`,
+		},
+		{
+			"database down",
+			map[string]storage.HealthCheck{
+				"database":   &testHealthCheck{err: errors.New("connection refused")},
+				"migrations": &testHealthCheck{details: &api.MigrationStats{Version: 2}},
+			},
+			http.StatusServiceUnavailable,
+			`{
+				"status": "unavailable",
+				"checks": {
+					"database": {"status": "down", "error": "connection refused"},
+					"migrations": {"status": "up", "details": {"version": 2, "pending": 0}}
+				}
+			}`,
+		},
+		{
+			"database hangs",
+			map[string]storage.HealthCheck{
+				"database": &testHealthCheck{block: true},
+			},
+			http.StatusServiceUnavailable,
+			`{
+				"status": "unavailable",
+				"checks": {
+					"database": {"status": "down", "error": "context deadline exceeded"}
+				}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest("GET", "/readyz", nil)
+
+			readiness(test.checks, 10*time.Millisecond)(ctx)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.JSONEq(t, test.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"example.com/playground/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "requestID"
+
+	redacted = "[REDACTED]"
+)
+
+// DefaultMaxBodyBytes is how much of a request body gets logged when the
+// logging config doesn't say otherwise.
+const DefaultMaxBodyBytes = 4 << 10
+
+// DefaultRedactedHeaders and DefaultRedactedFields are always masked, on
+// top of whatever the logging config adds. Cursors carry the sort values of
+// the offer they point at and q what someone searched for, so both are
+// masked in query strings.
+var (
+	DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	DefaultRedactedFields  = []string{"email", "phone", "password", "token", "cursor", "q"}
+)
+
+// LoggingConfig controls what loggingMiddleware writes about each request.
+// RedactedHeaders and RedactedFields are masked in addition to the
+// defaults, the fields both in JSON bodies and in query strings. A zero
+// MaxBodyBytes falls back to DefaultMaxBodyBytes, a negative one turns body
+// logging off.
+type LoggingConfig struct {
+	RedactedHeaders []string
+	RedactedFields  []string
+	MaxBodyBytes    int
+}
+
+// validRequestID bounds what we accept from clients so that a propagated
+// id can't be used to inject arbitrary content into logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDMiddleware reuses the caller's X-Request-ID when it looks sane
+// and generates one otherwise, echoing it on the response.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.Must(uuid.NewV4()).String()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(requestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func loggingMiddleware(lg *zap.SugaredLogger, cfg *LoggingConfig) gin.HandlerFunc {
+	if cfg == nil {
+		cfg = &LoggingConfig{}
+	}
+
+	maxBody := cfg.MaxBodyBytes
+	if maxBody == 0 {
+		maxBody = DefaultMaxBodyBytes
+	}
+
+	headers := make(map[string]bool)
+	for _, list := range [][]string{DefaultRedactedHeaders, cfg.RedactedHeaders} {
+		for _, h := range list {
+			headers[http.CanonicalHeaderKey(h)] = true
+		}
+	}
+
+	fields := make(map[string]bool)
+	for _, list := range [][]string{DefaultRedactedFields, cfg.RedactedFields} {
+		for _, f := range list {
+			fields[strings.ToLower(f)] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		var body []byte
+		var truncated bool
+		if maxBody > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			var err error
+			body, truncated, err = peekBody(c.Request, maxBody)
+			if err != nil {
+				lg.Errorw("reading request body failed", "request_id", requestID(c), "error", err)
+			}
+		}
+
+		c.Next()
+
+		kv := []interface{}{
+			"request_id", requestID(c),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"query", redactQuery(c.Request.URL.RawQuery, fields),
+			"route", c.FullPath(),
+			"client_ip", c.ClientIP(),
+			"header", redactHeader(c.Request.Header, headers),
+			"status", c.Writer.Status(),
+			"latency", time.Since(start),
+			"bytes", c.Writer.Size(),
+		}
+
+		if body != nil {
+			kv = append(kv, "body", redactBody(body, truncated, fields))
+		}
+
+		if p := auth.FromContext(c.Request.Context()); p != nil {
+			kv = append(kv, "subject", p.Subject)
+		}
+
+		if len(c.Errors) > 0 {
+			kv = append(kv, "error", c.Errors.Last().Error())
+		}
+
+		kv = append(kv, traceFields(c)...)
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			lg.Errorw("request", kv...)
+		} else {
+			lg.Infow("request", kv...)
+		}
+	}
+}
+
+// peekBody reads at most max bytes of the request body for logging and
+// puts them back in front of the unread remainder, so the handler still
+// sees the whole body without it all being buffered.
+func peekBody(r *http.Request, max int) ([]byte, bool, error) {
+	buf := make([]byte, max+1)
+
+	n, err := io.ReadFull(r.Body, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+
+	buf = buf[:n]
+
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+
+	if n > max {
+		return buf[:max], true, err
+	}
+
+	return buf, false, err
+}
+
+func redactHeader(h http.Header, redact map[string]bool) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if redact[k] {
+			out[k] = []string{redacted}
+		} else {
+			out[k] = v
+		}
+	}
+
+	return out
+}
+
+// redactQuery masks the values of the configured fields in a raw query
+// string, leaving the rest as the client sent it.
+func redactQuery(raw string, fields map[string]bool) string {
+	if raw == "" {
+		return ""
+	}
+
+	params := strings.Split(raw, "&")
+	for i, p := range params {
+		key := p
+		if j := strings.IndexByte(p, '='); j >= 0 {
+			key = p[:j]
+		}
+
+		name := key
+		if k, err := url.QueryUnescape(key); err == nil {
+			name = k
+		}
+
+		if fields[strings.ToLower(name)] {
+			params[i] = key + "=" + redacted
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// redactBody masks the configured fields of a JSON body. Bodies that can't
+// be parsed, including ones cut short at the capture limit, are left out
+// entirely since there is no telling what they contain.
+func redactBody(b []byte, truncated bool, fields map[string]bool) string {
+	if len(b) == 0 {
+		return ""
+	}
+
+	if truncated {
+		return "[TRUNCATED]"
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "[UNPARSEABLE]"
+	}
+
+	out, err := json.Marshal(redactValue(v, fields))
+	if err != nil {
+		return "[UNPARSEABLE]"
+	}
+
+	return string(out)
+}
+
+func redactValue(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if fields[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(field, fields)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, fields)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggingRedaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          *LoggingConfig
+		body         string
+		expectedBody interface{}
+	}{
+		{
+			"default fields",
+			nil,
+			`{"company":"ACME","email":"jane@example.com","phone":"+38970000000"}`,
+			`{"company":"ACME","email":"[REDACTED]","phone":"[REDACTED]"}`,
+		},
+		{
+			"nested fields, case insensitive",
+			&LoggingConfig{RedactedFields: []string{"Secret"}},
+			`[{"inner":{"secret":"x","keep":"y"}}]`,
+			`[{"inner":{"keep":"y","secret":"[REDACTED]"}}]`,
+		},
+		{
+			"configured fields add to the defaults",
+			&LoggingConfig{RedactedFields: []string{"company"}, RedactedHeaders: []string{"X-Custom"}},
+			`{"company":"ACME","email":"jane@example.com"}`,
+			`{"company":"[REDACTED]","email":"[REDACTED]"}`,
+		},
+		{
+			"truncated",
+			&LoggingConfig{MaxBodyBytes: 8},
+			`{"email":"jane@example.com"}`,
+			"[TRUNCATED]",
+		},
+		{
+			"not json",
+			nil,
+			`email=jane@example.com`,
+			"[UNPARSEABLE]",
+		},
+		{
+			"body logging disabled",
+			&LoggingConfig{MaxBodyBytes: -1},
+			`{"company":"ACME"}`,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			var received string
+
+			gin.SetMode(gin.TestMode)
+			e := gin.New()
+			e.Use(requestIDMiddleware(), loggingMiddleware(zap.New(core).Sugar(), test.cfg))
+			e.POST("/offers", func(c *gin.Context) {
+				b, _ := io.ReadAll(c.Request.Body)
+				received = string(b)
+				c.Status(http.StatusCreated)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/offers", strings.NewReader(test.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("Accept", "application/json")
+			e.ServeHTTP(w, req)
+
+			assert.Equal(t, test.body, received, "handler must see the whole body")
+
+			entries := logs.FilterMessage("request").All()
+			require.Len(t, entries, 1)
+
+			fields := entries[0].ContextMap()
+			assert.Equal(t, test.expectedBody, fields["body"])
+			assert.Equal(t, int64(http.StatusCreated), fields["status"])
+			assert.Equal(t, "/offers", fields["route"])
+			assert.Contains(t, fields, "latency")
+			assert.Contains(t, fields, "bytes")
+
+			// The defaults are masked whatever the config adds.
+			header := fields["header"].(http.Header)
+			assert.Equal(t, []string{"[REDACTED]"}, header["Authorization"])
+			assert.Equal(t, []string{"application/json"}, header["Accept"])
+			assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"), "request headers must be left alone")
+		})
+	}
+}
+
+func TestLoggingQueryRedaction(t *testing.T) {
+	tests := []struct {
+		cfg           *LoggingConfig
+		query         string
+		expectedQuery string
+	}{
+		{nil, "", ""},
+		{nil, "size=10&company=ACME", "size=10&company=ACME"},
+		{nil, "q=golang&size=10&cursor=abc.def", "q=[REDACTED]&size=10&cursor=[REDACTED]"},
+		{nil, "Email=jane%40example.com&mine", "Email=[REDACTED]&mine"},
+		{nil, "%71=golang", "%71=[REDACTED]"},
+		{&LoggingConfig{RedactedFields: []string{"company"}}, "company=ACME&q", "company=[REDACTED]&q=[REDACTED]"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			gin.SetMode(gin.TestMode)
+			e := gin.New()
+			e.Use(loggingMiddleware(zap.New(core).Sugar(), test.cfg))
+			e.GET("/offers", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/offers?"+test.query, nil))
+
+			entries := logs.FilterMessage("request").All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, test.expectedQuery, entries[0].ContextMap()["query"])
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		header    string
+		propagate bool
+	}{
+		{"", false},
+		{"req-1", true},
+		{"b7f3c2a0-1d5e-4c5f-8f3b-0c6a2e9d4f11", true},
+		{"bad id\nwith newline", false},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%q", test.header), func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			gin.SetMode(gin.TestMode)
+			r := SetupRouteHandlers(&RouteHandlers{}, zap.New(core).Sugar())
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/nowhere", nil)
+			req.Header.Set("X-Request-ID", test.header)
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get("X-Request-ID")
+			if test.propagate {
+				assert.Equal(t, test.header, id)
+			} else {
+				assert.Regexp(t, `^[0-9a-f-]{36}$`, id)
+			}
+
+			assert.JSONEq(t, fmt.Sprintf(`{
+				"code": "not_found",
+				"message": "route not found",
+				"request_id": %q
+			}`, id), w.Body.String())
+
+			entries := logs.All()
+			require.Len(t, entries, 1)
+			assert.Equal(t, id, entries[0].ContextMap()["request_id"])
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type httpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func newHTTPMetrics(reg prometheus.Registerer) *httpMetrics {
+	m := &httpMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "offers",
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "offers",
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+
+	reg.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// metricsMiddleware labels requests by route template rather than raw path
+// to keep the label cardinality bounded.
+func metricsMiddleware(m *httpMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.requests.WithLabelValues(c.Request.Method, route, status).Inc()
+		m.duration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+func metricsHandler(reg *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+}
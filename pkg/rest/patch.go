@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/is"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	errMergePatchMediaType = errors.New("content type must be " + mergePatchContentType)
+	errMergePatchNotObject = errors.New("merge patch must be a JSON object")
+)
+
+func patch(p offers.PatchOffer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		var request api.PatchJobOfferRequest
+		if err := bindMergePatch(c, &request); err != nil {
+			if errors.Is(err, errMergePatchMediaType) {
+				c.Header("Accept-Patch", mergePatchContentType)
+				abortWithError(c, http.StatusUnsupportedMediaType, err)
+			} else {
+				abortWithError(c, http.StatusBadRequest, err)
+			}
+
+			return
+		}
+
+		if err := request.Validate(); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		offerID := c.Param("offerID")
+
+		if err := validation.Validate(offerID,
+			validation.Required,
+			is.UUID,
+		); err != nil {
+			abortWithError(c, http.StatusBadRequest, validation.Errors{"offerID": err})
+
+			return
+		}
+
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		resp, err := p.Patch(c.Request.Context(), offerID, version, &request)
+		if err != nil {
+			abortWithError(c, storageErrorStatus(err), err)
+
+			return
+		}
+
+		setETag(c, resp)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// bindMergePatch decodes an RFC 7396 merge patch into v. Plain JSON is
+// accepted as well, but the document has to be an object: any other value
+// would replace the whole offer, which PUT is for. Unknown members are
+// rejected rather than silently ignored.
+func bindMergePatch(c *gin.Context, v interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(c.ContentType())
+	if err != nil || (mediaType != mergePatchContentType && mediaType != gin.MIMEJSON) {
+		return errMergePatchMediaType
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&raw); err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(raw, []byte("{")) {
+		return errMergePatchNotObject
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
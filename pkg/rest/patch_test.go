@@ -0,0 +1,181 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPatchOffer struct {
+	patchOfferCalled int
+	patchOfferErr    error
+	request          *api.PatchJobOfferRequest
+}
+
+func (p *testPatchOffer) Patch(_ context.Context, _ string, _ int64, req *api.PatchJobOfferRequest) (*api.JobOfferResponse, error) {
+	p.patchOfferCalled++
+	p.request = req
+	return &api.JobOfferResponse{}, p.patchOfferErr
+}
+
+func TestPatchOffer(t *testing.T) {
+	tests := []struct {
+		contentType     string
+		body            string
+		patchErr        error
+		expectedCalls   int
+		expectedStatus  int
+		expectedRequest *api.PatchJobOfferRequest
+	}{
+		{
+			"application/merge-patch+json",
+			`{"salary": 9000}`,
+			nil,
+			1,
+			http.StatusOK,
+			&api.PatchJobOfferRequest{
+				Salary: api.OptionalFloat{Set: true, Valid: true, Float64: 9000},
+			},
+		},
+		{
+			"application/json; charset=utf-8",
+			`{"details": null, "expiration_date": null, "company": "ACME"}`,
+			nil,
+			1,
+			http.StatusOK,
+			&api.PatchJobOfferRequest{
+				Company:        api.OptionalString{Set: true, Valid: true, String: "ACME"},
+				Details:        api.OptionalString{Set: true},
+				ExpirationDate: api.OptionalString{Set: true},
+			},
+		},
+		{
+			"application/merge-patch+json",
+			`{}`,
+			nil,
+			1,
+			http.StatusOK,
+			&api.PatchJobOfferRequest{},
+		},
+		{
+			"text/plain",
+			`{"salary": 9000}`,
+			nil,
+			0,
+			http.StatusUnsupportedMediaType,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`null`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"salary": 9000`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"salery": 9000}`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"company": null}`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"email": "not an email"}`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"expiration_date": ""}`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"expiration_date": "2020-03-01"}`,
+			nil,
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"application/merge-patch+json",
+			`{"salary": 9000}`,
+			storage.ErrNotFound,
+			1,
+			http.StatusNotFound,
+			&api.PatchJobOfferRequest{
+				Salary: api.OptionalFloat{Set: true, Valid: true, Float64: 9000},
+			},
+		},
+		{
+			"application/merge-patch+json",
+			`{"salary": 9000}`,
+			offers.ErrForbidden,
+			1,
+			http.StatusForbidden,
+			&api.PatchJobOfferRequest{
+				Salary: api.OptionalFloat{Set: true, Valid: true, Float64: 9000},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			p := testPatchOffer{
+				patchOfferErr: test.patchErr,
+			}
+
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Params = []gin.Param{
+				{
+					Key:   "offerID",
+					Value: "eca51142-3bf0-4766-baf7-2a168c964024",
+				},
+			}
+			ctx.Request = httptest.NewRequest("PATCH", "/offers/eca51142-3bf0-4766-baf7-2a168c964024", strings.NewReader(test.body))
+			ctx.Request.Header.Set("Content-Type", test.contentType)
+
+			patch(&p)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedCalls, p.patchOfferCalled)
+			assert.Equal(t, test.expectedRequest, p.request)
+		})
+	}
+}
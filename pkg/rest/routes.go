@@ -1,62 +1,94 @@
 package rest
 
 import (
-	"bytes"
-	"io"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/offers"
 	"example.com/playground/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-ozzo/ozzo-validation/is"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
 func SetupRouteHandlers(r *RouteHandlers, lg *zap.SugaredLogger) *gin.Engine {
 	e := gin.New()
-	e.Use(gin.Recovery())
-	e.Use(loggingMiddleware(lg))
+	e.Use(requestIDMiddleware())
+	e.Use(tracingMiddleware())
+	e.Use(loggingMiddleware(lg, r.Logging))
+	e.Use(gin.CustomRecovery(recoveryHandler))
+	if r.Metrics != nil {
+		e.Use(metricsMiddleware(newHTTPMetrics(r.Metrics)))
+	}
+	e.Use(errorMiddleware())
+	e.NoRoute(func(c *gin.Context) {
+		abortWithError(c, http.StatusNotFound, errRouteNotFound)
+	})
 	e.SetTrustedProxies(nil)
 	return r.routes(e)
 }
 
-func loggingMiddleware(lg *zap.SugaredLogger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var b []byte
-		if c.Request.Body != nil {
-			buf := new(bytes.Buffer)
-
-			if _, err := buf.ReadFrom(c.Request.Body); err != nil {
-				lg.Errorf("serialization error", "error", err)
-
-				return
-			}
-
-			b = buf.Bytes()
-
-			c.Request.Body = io.NopCloser(buf)
-		}
-
-		c.Next()
-
-		lg.Infow("request",
-			"requestURI", c.Request.RequestURI,
-			"header", c.Request.Header,
-			"host", c.Request.Host,
-			"method", c.Request.Method,
-			"body", string(b))
+func storageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrConflict), errors.Is(err, offers.ErrExpired):
+		return http.StatusConflict
+	case errors.Is(err, offers.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, offers.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, storage.ErrInvalidCursor):
+		return http.StatusBadRequest
+	case errors.As(err, &validation.Errors{}):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
 }
 
 type RouteHandlers struct {
-	CreateOffer  storage.CreateOffer
-	UpdateOffer  storage.UpdateOffer
-	GetOffer     storage.GetOffer
-	GetAllOffers storage.GetAllOffers
-	DeleteOffer  storage.DeleteOffer
+	CreateOffer  offers.CreateOffer
+	UpdateOffer  offers.UpdateOffer
+	PatchOffer   offers.PatchOffer
+	GetOffer     offers.GetOffer
+	GetAllOffers offers.GetAllOffers
+	DeleteOffer  offers.DeleteOffer
+	ExportOffers offers.ExportOffers
+
+	GetTrash     offers.GetTrash
+	RestoreOffer offers.RestoreOffer
+	PurgeOffer   offers.PurgeOffer
+
+	BatchCreateOffers offers.BatchCreateOffers
+	BatchPatchOffers  offers.BatchPatchOffers
+	BatchDeleteOffers offers.BatchDeleteOffers
+
+	ReadinessChecks  map[string]storage.HealthCheck
+	ReadinessTimeout time.Duration
+
+	Metrics     *prometheus.Registry
+	MetricsPath string
+
+	Logging *LoggingConfig
+
+	// Auth identifies callers of the offers endpoints. Leaving it nil
+	// turns authentication off.
+	Auth auth.Authenticator
+
+	// RequireIfMatch makes PUT, PATCH and DELETE on an offer fail with 428
+	// unless they carry an If-Match header, and so the items of batch
+	// patches and deletes unless they carry a version.
+	RequireIfMatch bool
 }
 
 func (r *RouteHandlers) routes(e *gin.Engine) *gin.Engine {
@@ -67,55 +99,211 @@ func (r *RouteHandlers) routes(e *gin.Engine) *gin.Engine {
 		})
 	})
 
-	e.POST("/offers", create(r.CreateOffer))
-	e.GET("/offers", getAll(r.GetAllOffers))
-	e.PUT("/offers/:offerID", update(r.UpdateOffer))
-	e.GET("/offers/:offerID", getByID(r.GetOffer))
-	e.DELETE("/offers/:offerID", delete(r.DeleteOffer))
+	e.GET("/healthz", liveness())
+	e.GET("/readyz", readiness(r.ReadinessChecks, r.ReadinessTimeout))
+
+	if r.Metrics != nil {
+		e.GET(r.MetricsPath, metricsHandler(r.Metrics))
+	}
+
+	e.POST("/offers", authorize(r.Auth, auth.RoleRecruiter), create(r.CreateOffer))
+	e.GET("/offers", authorize(r.Auth, auth.RoleViewer), getAll(r.GetAllOffers))
+	e.PUT("/offers/:offerID", authorize(r.Auth, auth.RoleRecruiter), requireIfMatch(r.RequireIfMatch), update(r.UpdateOffer))
+	e.PATCH("/offers/:offerID", authorize(r.Auth, auth.RoleRecruiter), requireIfMatch(r.RequireIfMatch), patch(r.PatchOffer))
+	e.GET("/offers/:offerID", authorize(r.Auth, auth.RoleViewer), getByID(r.GetOffer))
+	e.GET("/offers/export", authorize(r.Auth, auth.RoleViewer), export(r.ExportOffers))
+	e.GET("/offers/trash", authorize(r.Auth, auth.RoleRecruiter), getTrash(r.GetTrash))
+	e.POST("/offers/:offerID/restore", authorize(r.Auth, auth.RoleRecruiter), restore(r.RestoreOffer))
+	e.DELETE("/offers/:offerID", authorize(r.Auth, auth.RoleRecruiter), requireIfMatch(r.RequireIfMatch), delete(r.DeleteOffer, r.PurgeOffer))
+	e.POST("/offers:batch", batchRoute(), authorize(r.Auth, auth.RoleRecruiter), createBatch(r.BatchCreateOffers))
+	e.PATCH("/offers:batch", batchRoute(), authorize(r.Auth, auth.RoleRecruiter), patchBatch(r.BatchPatchOffers, r.RequireIfMatch))
+	e.DELETE("/offers:batch", batchRoute(), authorize(r.Auth, auth.RoleRecruiter), deleteBatch(r.BatchDeleteOffers, r.RequireIfMatch))
 
 	return e
 }
 
-func getAll(g storage.GetAllOffers) gin.HandlerFunc {
+func getAll(g offers.GetAllOffers) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer c.Header("Content-Type", "application/json")
 
-		size := c.DefaultQuery("size", "2")
-		offset := c.DefaultQuery("offset", "0")
-		sortBy := c.DefaultQuery("sortBy", "company")
-
-		if err := validation.Validate(sortBy, validation.In("uuid", "id", "company", "email", "details", "salary", "phone")); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+		page, err := parsePage(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
 
-		sizeInt, err := strconv.Atoi(size)
+		filter, err := parseOffersFilter(c)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
 
-		offsetInt, err := strconv.Atoi(offset)
+		resp, err := g.GetAll(c.Request.Context(), page, filter)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, storageErrorStatus(err), err)
 
 			return
 		}
 
-		resp, err := g.GetAll(c.Request.Context(), sizeInt, offsetInt, sortBy)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// parsePage reads the size, offset, cursor and sort of a listing.
+func parsePage(c *gin.Context) (*api.PageRequest, error) {
+	size := c.DefaultQuery("size", "2")
+	offset := c.DefaultQuery("offset", "0")
+
+	sort, err := parseSort(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sizeInt, err := strconv.Atoi(size)
+	if err != nil {
+		return nil, validation.Errors{"size": errNotInteger}
+	}
+
+	offsetInt, err := strconv.Atoi(offset)
+	if err != nil {
+		return nil, validation.Errors{"offset": errNotInteger}
+	}
+
+	page := &api.PageRequest{
+		Size:   sizeInt,
+		Offset: offsetInt,
+		Cursor: c.Query("cursor"),
+		Sort:   sort,
+	}
+
+	if err := page.Validate(); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// parseSort reads comma separated sort keys such as "-salary,company" from
+// the sort query parameter, prefixing a field with "-" to sort descending.
+// The legacy sortBy parameter is still honoured as a single ascending key.
+func parseSort(c *gin.Context) ([]api.SortKey, error) {
+	param, spec := "sort", c.Query("sort")
+	if spec == "" {
+		param, spec = "sortBy", c.DefaultQuery("sortBy", "company")
+	}
+
+	seen := make(map[string]bool)
+	sort := make([]api.SortKey, 0)
+
+	for _, s := range strings.Split(spec, ",") {
+		// A "+" prefix arrives as a space unless the client escapes it.
+		s = strings.TrimSpace(s)
+
+		key := api.SortKey{
+			Field:      strings.TrimLeft(s, "+-"),
+			Descending: strings.HasPrefix(s, "-"),
+		}
+
+		if !storage.IsSortable(key.Field) {
+			return nil, validation.Errors{param: fmt.Errorf("cannot sort by %q", s)}
+		}
+
+		if seen[key.Field] {
+			return nil, validation.Errors{param: fmt.Errorf("%q is listed more than once", key.Field)}
+		}
+
+		seen[key.Field] = true
+		sort = append(sort, key)
+	}
+
+	return sort, nil
+}
+
+func parseOffersFilter(c *gin.Context) (*api.OffersFilter, error) {
+	errs := validation.Errors{}
+
+	parseFloat := func(key string) *float64 {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			return nil
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			errs[key] = errNotNumber
 
-			return
+			return nil
 		}
 
-		c.JSON(http.StatusOK, resp)
+		return &f
+	}
+
+	parseTime := func(key string) *time.Time {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			return nil
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			errs[key] = errNotTimestamp
+
+			return nil
+		}
+
+		return &t
+	}
+
+	parseBool := func(key string) bool {
+		v, ok := c.GetQuery(key)
+		if !ok {
+			return false
+		}
+
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs[key] = errNotBoolean
+
+			return false
+		}
+
+		return b
+	}
+
+	filter := &api.OffersFilter{
+		Company:        c.Query("company"),
+		MinSalary:      parseFloat("min_salary"),
+		MaxSalary:      parseFloat("max_salary"),
+		ExpiresAfter:   parseTime("expires_after"),
+		ExpiresBefore:  parseTime("expires_before"),
+		Query:          c.Query("q"),
+		IncludeExpired: parseBool("include_expired"),
+	}
+
+	if parseBool("mine") {
+		if p := auth.FromContext(c.Request.Context()); p != nil {
+			filter.Owner = p.Subject
+		} else {
+			errs["mine"] = errNoCaller
+		}
 	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
 }
 
-func delete(d storage.DeleteOffer) gin.HandlerFunc {
+// delete moves the offer to the trash, or deletes it for good with
+// ?purge=true.
+func delete(d offers.DeleteOffer, p offers.PurgeOffer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer c.Header("Content-Type", "application/json")
 
@@ -125,14 +313,31 @@ func delete(d storage.DeleteOffer) gin.HandlerFunc {
 			validation.Required,
 			is.UUID,
 		); err != nil {
-			_ = c.AbortWithError(http.StatusUnprocessableEntity, err)
+			abortWithError(c, http.StatusUnprocessableEntity, validation.Errors{"offerID": err})
 
 			return
 		}
 
-		err := d.DeleteByID(c.Request.Context(), offerID)
+		purge, err := parsePurge(c)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		version, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		if purge {
+			err = p.Purge(c.Request.Context(), offerID, version)
+		} else {
+			err = d.DeleteByID(c.Request.Context(), offerID, version)
+		}
+
+		if err != nil {
+			abortWithError(c, storageErrorStatus(err), err)
 
 			return
 		}
@@ -142,26 +347,26 @@ func delete(d storage.DeleteOffer) gin.HandlerFunc {
 	}
 }
 
-func create(cr storage.CreateOffer) gin.HandlerFunc {
+func create(cr offers.CreateOffer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer c.Header("Content-Type", "application/json")
 
 		var request api.JobOfferRequest
-		if err := c.BindJSON(&request); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
 
 		if err := request.Validate(); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
 
 		resp, err := cr.Create(c.Request.Context(), &request)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, storageErrorStatus(err), err)
 
 			return
 		}
@@ -170,19 +375,19 @@ func create(cr storage.CreateOffer) gin.HandlerFunc {
 	}
 }
 
-func update(u storage.UpdateOffer) gin.HandlerFunc {
+func update(u offers.UpdateOffer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer c.Header("Content-Type", "application/json")
 
 		var request api.UpdateJobOfferRequest
-		if err := c.BindJSON(&request); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
 
 		if err := request.Validate(); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, err)
 
 			return
 		}
@@ -193,23 +398,29 @@ func update(u storage.UpdateOffer) gin.HandlerFunc {
 			validation.Required,
 			is.UUID,
 		); err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithError(c, http.StatusBadRequest, validation.Errors{"offerID": err})
+
+			return
+		}
 
+		version, ok := ifMatchVersion(c)
+		if !ok {
 			return
 		}
 
-		resp, err := u.Update(c.Request.Context(), offerID, &request)
+		resp, err := u.Update(c.Request.Context(), offerID, version, &request)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, storageErrorStatus(err), err)
 
 			return
 		}
 
+		setETag(c, resp)
 		c.JSON(http.StatusOK, resp)
 	}
 }
 
-func getByID(g storage.GetOffer) gin.HandlerFunc {
+func getByID(g offers.GetOffer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer c.Header("Content-Type", "application/json")
 
@@ -219,14 +430,22 @@ func getByID(g storage.GetOffer) gin.HandlerFunc {
 			validation.Required,
 			is.UUID,
 		); err != nil {
-			_ = c.AbortWithError(http.StatusUnprocessableEntity, err)
+			abortWithError(c, http.StatusUnprocessableEntity, validation.Errors{"offerID": err})
 
 			return
 		}
 
 		resp, err := g.Get(c.Request.Context(), offerID)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithError(c, storageErrorStatus(err), err)
+
+			return
+		}
+
+		setETag(c, resp)
+
+		if notModified(c, resp.Version) {
+			c.Status(http.StatusNotModified)
 
 			return
 		}
@@ -9,10 +9,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/auth"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
@@ -32,9 +37,15 @@ func TestRoutes(t *testing.T) {
 	}
 
 	assert.Equal(t, map[string][]string{
-		"/ping":            {"GET"},
-		"/offers":          {"GET", "POST"},
-		"/offers/:offerID": {"GET", "PUT", "DELETE"},
+		"/ping":                    {"GET"},
+		"/healthz":                 {"GET"},
+		"/readyz":                  {"GET"},
+		"/offers":                  {"GET", "POST"},
+		"/offers/:offerID":         {"GET", "PUT", "PATCH", "DELETE"},
+		"/offers/:offerID/restore": {"POST"},
+		"/offers/export":           {"GET"},
+		"/offers/trash":            {"GET"},
+		"/offers:batch":            {"POST", "PATCH", "DELETE"},
 	}, routes)
 
 }
@@ -43,7 +54,7 @@ func TestMiddllewareLogging(t *testing.T) {
 	observedZapCore, observedLogs := observer.New(zap.InfoLevel)
 	observedLogger := zap.New(observedZapCore)
 
-	sut := loggingMiddleware(observedLogger.Sugar())
+	sut := loggingMiddleware(observedLogger.Sugar(), nil)
 
 	w := httptest.NewRecorder()
 	ctx, _ := gin.CreateTestContext(w)
@@ -61,14 +72,20 @@ func TestMiddllewareLogging(t *testing.T) {
 
 type testDeleteOffer struct {
 	deleteOfferCalled int
+	purgeOfferCalled  int
 	deleteOfferErr    error
 }
 
-func (d *testDeleteOffer) DeleteByID(ctx context.Context, offerID string) error {
+func (d *testDeleteOffer) DeleteByID(ctx context.Context, offerID string, version int64) error {
 	d.deleteOfferCalled++
 	return d.deleteOfferErr
 }
 
+func (d *testDeleteOffer) Purge(ctx context.Context, offerID string, version int64) error {
+	d.purgeOfferCalled++
+	return d.deleteOfferErr
+}
+
 func TestDeleteOffer(t *testing.T) {
 	tests := []struct {
 		offerID        string
@@ -88,6 +105,24 @@ func TestDeleteOffer(t *testing.T) {
 			http.StatusInternalServerError,
 			errors.New("oops..something went wrong"),
 		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			1,
+			http.StatusNotFound,
+			storage.ErrNotFound,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			1,
+			http.StatusConflict,
+			storage.ErrConflict,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			1,
+			http.StatusForbidden,
+			offers.ErrForbidden,
+		},
 	}
 
 	for _, test := range tests {
@@ -107,7 +142,7 @@ func TestDeleteOffer(t *testing.T) {
 			}
 			ctx.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/offers/%s", test.offerID), nil)
 
-			delete(&do)(ctx)
+			delete(&do, &do)(ctx)
 
 			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
 			assert.Equal(t, test.expectedCalls, do.deleteOfferCalled)
@@ -115,6 +150,47 @@ func TestDeleteOffer(t *testing.T) {
 	}
 }
 
+func TestDeleteOfferPurge(t *testing.T) {
+	tests := []struct {
+		query          string
+		expectedDelete int
+		expectedPurge  int
+		expectedStatus int
+		expectedErr    error
+	}{
+		{"purge=true", 0, 1, http.StatusOK, nil},
+		{"purge=false", 1, 0, http.StatusOK, nil},
+		{"purge=yes", 0, 0, http.StatusBadRequest, nil},
+		{"purge=true", 0, 1, http.StatusForbidden, offers.ErrForbidden},
+		{"purge=true", 0, 1, http.StatusPreconditionFailed, offers.ErrVersionMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			do := testDeleteOffer{
+				deleteOfferErr: test.expectedErr,
+			}
+
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Params = []gin.Param{
+				{
+					Key:   "offerID",
+					Value: "eca51142-3bf0-4766-baf7-2a168c964024",
+				},
+			}
+			ctx.Request = httptest.NewRequest("DELETE", "/offers/eca51142-3bf0-4766-baf7-2a168c964024?"+test.query, nil)
+
+			delete(&do, &do)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedDelete, do.deleteOfferCalled)
+			assert.Equal(t, test.expectedPurge, do.purgeOfferCalled)
+		})
+	}
+}
+
 type testCreateOffer struct {
 	createOfferCalled int
 	createOfferErr    error
@@ -136,7 +212,7 @@ func TestCreateOffer(t *testing.T) {
 			&api.JobOfferRequest{
 				Company:        "TEST",
 				Email:          "test@hr-test.com",
-				ExpirationDate: "2022-03-01 14:30:00.00000",
+				ExpirationDate: "2030-03-01T14:30:00Z",
 				LinkToOffer:    "http://test.com/carriers",
 				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
 				Salary:         18000,
@@ -150,7 +226,7 @@ func TestCreateOffer(t *testing.T) {
 			&api.JobOfferRequest{
 				Company:        "TEST",
 				Email:          "test@hr-test.com",
-				ExpirationDate: "2022-03-01 14:30:00.00000",
+				ExpirationDate: "2030-03-01T14:30:00Z",
 				LinkToOffer:    "http://test.com/carriers",
 				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
 				Salary:         18000,
@@ -160,10 +236,80 @@ func TestCreateOffer(t *testing.T) {
 			http.StatusInternalServerError,
 			errors.New("oops..something went wrong"),
 		},
+		{
+			&api.JobOfferRequest{
+				Company:        "TEST",
+				Email:          "test@hr-test.com",
+				ExpirationDate: "2030-03-01T14:30:00Z",
+				LinkToOffer:    "http://test.com/carriers",
+				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
+				Salary:         18000,
+				ContactPhone:   "+38978653534",
+			},
+			1,
+			http.StatusConflict,
+			storage.ErrConflict,
+		},
+		{
+			&api.JobOfferRequest{
+				Company:        "TEST",
+				Email:          "test@hr-test.com",
+				ExpirationDate: "2030-03-01",
+				LinkToOffer:    "http://test.com/carriers",
+				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
+				Salary:         18000,
+				ContactPhone:   "+38978653534",
+			},
+			1,
+			http.StatusCreated,
+			nil,
+		},
+		{
+			&api.JobOfferRequest{
+				Company:        "TEST",
+				Email:          "test@hr-test.com",
+				ExpirationDate: "",
+				LinkToOffer:    "http://test.com/carriers",
+				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
+				Salary:         18000,
+				ContactPhone:   "+38978653534",
+			},
+			1,
+			http.StatusCreated,
+			nil,
+		},
+		{
+			&api.JobOfferRequest{
+				Company:        "TEST",
+				Email:          "test@hr-test.com",
+				ExpirationDate: "2030-03-01 14:30:00.00000",
+				LinkToOffer:    "http://test.com/carriers",
+				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
+				Salary:         18000,
+				ContactPhone:   "+38978653534",
+			},
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			&api.JobOfferRequest{
+				Company:        "TEST",
+				Email:          "test@hr-test.com",
+				ExpirationDate: "2020-03-01T14:30:00Z",
+				LinkToOffer:    "http://test.com/carriers",
+				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
+				Salary:         18000,
+				ContactPhone:   "+38978653534",
+			},
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
 		{
 			&api.JobOfferRequest{
 				Email:          "test@hr-test.com",
-				ExpirationDate: "2022-03-01 14:30:00.00000",
+				ExpirationDate: "2030-03-01T14:30:00Z",
 				LinkToOffer:    "http://test.com/carriers",
 				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
 				Salary:         18000,
@@ -177,7 +323,7 @@ func TestCreateOffer(t *testing.T) {
 			&api.JobOfferRequest{
 				Company:        "TEST_test",
 				Email:          "test@hr-test.com",
-				ExpirationDate: "2022-03-01 14:30:00.00000",
+				ExpirationDate: "2030-03-01T14:30:00Z",
 				LinkToOffer:    "http://test.com/carriers",
 				Details:        "We are looking for a Ninja Golang developer to work on our system serving...",
 				Salary:         18000,
@@ -218,7 +364,7 @@ type testUpdateOffer struct {
 	updateOfferErr    error
 }
 
-func (t *testUpdateOffer) Update(context.Context, string, *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error) {
+func (t *testUpdateOffer) Update(context.Context, string, int64, *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error) {
 	t.updateOfferCalled++
 	return &api.JobOfferResponse{}, t.updateOfferErr
 }
@@ -234,6 +380,7 @@ func TestUpdateOffer(t *testing.T) {
 		{
 			"eca51142-3bf0-4766-baf7-2a168c964024",
 			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
 				Salary:       8500,
 				Email:        "hey@outlook.com",
 				ContactPhone: "+38978360298",
@@ -246,6 +393,7 @@ func TestUpdateOffer(t *testing.T) {
 		{
 			"eca51142-3bf0-4766-baf7-2a168c964024",
 			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
 				Salary:       8500,
 				Email:        "hey@outlook.com",
 				ContactPhone: "+38978360298",
@@ -255,6 +403,45 @@ func TestUpdateOffer(t *testing.T) {
 			http.StatusInternalServerError,
 			errors.New("oops..something went wrong"),
 		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
+				Salary:       8500,
+				Email:        "hey@outlook.com",
+				ContactPhone: "+38978360298",
+				LinkToOffer:  "http://test.com/carrers",
+			},
+			1,
+			http.StatusNotFound,
+			storage.ErrNotFound,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
+				Salary:       8500,
+				Email:        "hey@outlook.com",
+				ContactPhone: "+38978360298",
+				LinkToOffer:  "http://test.com/carrers",
+			},
+			1,
+			http.StatusConflict,
+			storage.ErrConflict,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
+				Salary:       8500,
+				Email:        "hey@outlook.com",
+				ContactPhone: "+38978360298",
+				LinkToOffer:  "http://test.com/carrers",
+			},
+			1,
+			http.StatusForbidden,
+			offers.ErrForbidden,
+		},
 		{
 			"eca51142-3bf0-4766-baf7-2a168c964024",
 			&api.UpdateJobOfferRequest{
@@ -267,9 +454,51 @@ func TestUpdateOffer(t *testing.T) {
 			http.StatusBadRequest,
 			nil,
 		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Company:        "TEST",
+				Salary:         8500,
+				Email:          "hey@outlook.com",
+				ContactPhone:   "+38978360298",
+				LinkToOffer:    "http://test.com/carrers",
+				Details:        "Now hiring a Golang developer",
+				ExpirationDate: "2030-03-01",
+			},
+			1,
+			http.StatusOK,
+			nil,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Salary:       8500,
+				Email:        "hey@outlook.com",
+				ContactPhone: "+38978360298",
+				LinkToOffer:  "http://test.com/carrers",
+			},
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			&api.UpdateJobOfferRequest{
+				Company:        "TEST",
+				Salary:         8500,
+				Email:          "hey@outlook.com",
+				ContactPhone:   "+38978360298",
+				LinkToOffer:    "http://test.com/carrers",
+				ExpirationDate: "2020-03-01",
+			},
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
 		{
 			"eca51142-3bf0-4766-baf7-2a168c9640",
 			&api.UpdateJobOfferRequest{
+				Company:      "TEST",
 				Salary:       8500,
 				Email:        "hey@outlook.com",
 				ContactPhone: "+38978360298",
@@ -339,6 +568,12 @@ func TestGetOfferByID(t *testing.T) {
 			http.StatusInternalServerError,
 			errors.New("oops..something went wrong"),
 		},
+		{
+			"eca51142-3bf0-4766-baf7-2a168c964024",
+			1,
+			http.StatusNotFound,
+			storage.ErrNotFound,
+		},
 	}
 
 	for _, test := range tests {
@@ -370,10 +605,14 @@ type testGetAllOffers struct {
 	getAllOffersCalled int
 	getAllOffersErr    error
 	paginationResponse *api.JobOffersPaginationResponse
+	page               *api.PageRequest
+	filter             *api.OffersFilter
 }
 
-func (t *testGetAllOffers) GetAll(ctx context.Context, size, offset int, sortBy string) (*api.JobOffersPaginationResponse, error) {
+func (t *testGetAllOffers) GetAll(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*api.JobOffersPaginationResponse, error) {
 	t.getAllOffersCalled++
+	t.page = page
+	t.filter = filter
 	return t.paginationResponse, t.getAllOffersErr
 
 }
@@ -450,3 +689,412 @@ func TestGetAllOffers(t *testing.T) {
 	}
 
 }
+
+func TestGetAllOffersPagination(t *testing.T) {
+	tests := []struct {
+		query          string
+		expectedCalls  int
+		expectedStatus int
+		expectedPage   *api.PageRequest
+		expectedErr    error
+	}{
+		{
+			"",
+			1,
+			http.StatusOK,
+			&api.PageRequest{Size: 2, Offset: 0, Sort: []api.SortKey{{Field: "company"}}},
+			nil,
+		},
+		{
+			"size=10&offset=20&sortBy=salary",
+			1,
+			http.StatusOK,
+			&api.PageRequest{Size: 10, Offset: 20, Sort: []api.SortKey{{Field: "salary"}}},
+			nil,
+		},
+		{
+			"size=10&cursor=eyJzIjoiaWQifQ.c2ln",
+			1,
+			http.StatusOK,
+			&api.PageRequest{Size: 10, Cursor: "eyJzIjoiaWQifQ.c2ln", Sort: []api.SortKey{{Field: "company"}}},
+			nil,
+		},
+		{
+			"cursor=forged",
+			1,
+			http.StatusBadRequest,
+			&api.PageRequest{Size: 2, Cursor: "forged", Sort: []api.SortKey{{Field: "company"}}},
+			storage.ErrInvalidCursor,
+		},
+		{
+			"size=0",
+			0,
+			http.StatusBadRequest,
+			nil,
+			nil,
+		},
+		{
+			"offset=-1",
+			0,
+			http.StatusBadRequest,
+			nil,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			g := testGetAllOffers{
+				getAllOffersErr: test.expectedErr,
+			}
+
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest("GET", "/offers?"+test.query, nil)
+
+			getAll(&g)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedCalls, g.getAllOffersCalled)
+			assert.Equal(t, test.expectedPage, g.page)
+		})
+	}
+}
+
+func TestGetAllOffersSort(t *testing.T) {
+	tests := []struct {
+		query          string
+		expectedStatus int
+		expectedSort   []api.SortKey
+	}{
+		{
+			"sort=-salary,company",
+			http.StatusOK,
+			[]api.SortKey{{Field: "salary", Descending: true}, {Field: "company"}},
+		},
+		{
+			"sort=%2Bexpiration_date,-created_at",
+			http.StatusOK,
+			[]api.SortKey{{Field: "expiration_date"}, {Field: "created_at", Descending: true}},
+		},
+		{
+			"sort=-updated_at&sortBy=company",
+			http.StatusOK,
+			[]api.SortKey{{Field: "updated_at", Descending: true}},
+		},
+		{
+			"sortBy=email",
+			http.StatusOK,
+			[]api.SortKey{{Field: "email"}},
+		},
+		{
+			"sort=-password",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"sort=salary,-salary",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"sort=salary,",
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"sort=salary%3Bdrop%20table%20job_offers",
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			g := testGetAllOffers{}
+
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest("GET", "/offers?"+test.query, nil)
+
+			getAll(&g)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+
+			if test.expectedSort != nil {
+				assert.Equal(t, test.expectedSort, g.page.Sort)
+			}
+		})
+	}
+}
+
+func TestGetAllOffersFilter(t *testing.T) {
+	minSalary, maxSalary := 1000.0, 5000.0
+	expiresAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query          string
+		expectedCalls  int
+		expectedStatus int
+		expectedFilter *api.OffersFilter
+	}{
+		{
+			"",
+			1,
+			http.StatusOK,
+			&api.OffersFilter{},
+		},
+		{
+			"include_expired=true",
+			1,
+			http.StatusOK,
+			&api.OffersFilter{IncludeExpired: true},
+		},
+		{
+			"include_expired=sometimes",
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"company=ACME&min_salary=1000&max_salary=5000&expires_after=2030-01-01T00:00:00Z&q=golang+developer",
+			1,
+			http.StatusOK,
+			&api.OffersFilter{
+				Company:      "ACME",
+				MinSalary:    &minSalary,
+				MaxSalary:    &maxSalary,
+				ExpiresAfter: &expiresAfter,
+				Query:        "golang developer",
+			},
+		},
+		{
+			"min_salary=lots",
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"min_salary=5000&max_salary=1000",
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"expires_before=2030-01-01",
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+		{
+			"expires_after=2030-01-02T00:00:00Z&expires_before=2030-01-01T00:00:00Z",
+			0,
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			g := testGetAllOffers{}
+
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest("GET", "/offers?"+test.query, nil)
+
+			getAll(&g)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedCalls, g.getAllOffersCalled)
+			assert.Equal(t, test.expectedFilter, g.filter)
+		})
+	}
+}
+
+func TestGetAllOffersMine(t *testing.T) {
+	tests := []struct {
+		query          string
+		principal      *auth.Principal
+		expectedStatus int
+		expectedFilter *api.OffersFilter
+	}{
+		{
+			"mine=true",
+			&auth.Principal{Subject: "rita", Role: auth.RoleRecruiter},
+			http.StatusOK,
+			&api.OffersFilter{Owner: "rita"},
+		},
+		{
+			"mine=false",
+			&auth.Principal{Subject: "rita", Role: auth.RoleRecruiter},
+			http.StatusOK,
+			&api.OffersFilter{},
+		},
+		{
+			"mine=true",
+			nil,
+			http.StatusBadRequest,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			g := testGetAllOffers{}
+
+			ctx, _ := gin.CreateTestContext(w)
+
+			ctx.Request = httptest.NewRequest("GET", "/offers?"+test.query, nil)
+			if test.principal != nil {
+				ctx.Request = ctx.Request.WithContext(auth.WithPrincipal(ctx.Request.Context(), test.principal))
+			}
+
+			getAll(&g)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedFilter, g.filter)
+		})
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	sut := SetupRouteHandlers(&RouteHandlers{
+		CreateOffer:  &testCreateOffer{},
+		GetOffer:     &testGetOffer{getOfferErr: storage.ErrNotFound},
+		GetAllOffers: &testGetAllOffers{getAllOffersErr: errors.New("dial tcp: connection refused")},
+	}, zaptest.NewLogger(t).Sugar())
+
+	tests := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedResp   api.ErrorResponse
+	}{
+		{
+			"POST",
+			"/offers",
+			`{"company": "TEST", "salary": 1000}`,
+			http.StatusBadRequest,
+			api.ErrorResponse{
+				Code:    api.CodeValidationFailed,
+				Message: "request validation failed",
+				Details: map[string]string{
+					"email":   "cannot be blank",
+					"details": "cannot be blank",
+					"link":    "cannot be blank",
+					"phone":   "cannot be blank",
+				},
+				RequestID: "req-1",
+			},
+		},
+		{
+			"POST",
+			"/offers",
+			`{"company": `,
+			http.StatusBadRequest,
+			api.ErrorResponse{
+				Code:      api.CodeBadRequest,
+				Message:   "unexpected EOF",
+				RequestID: "req-1",
+			},
+		},
+		{
+			"GET",
+			"/offers/eca51142-3bf0-4766-baf7-2a168c964024",
+			"",
+			http.StatusNotFound,
+			api.ErrorResponse{
+				Code:      api.CodeNotFound,
+				Message:   storage.ErrNotFound.Error(),
+				RequestID: "req-1",
+			},
+		},
+		{
+			"GET",
+			"/offers?size=ten",
+			"",
+			http.StatusBadRequest,
+			api.ErrorResponse{
+				Code:      api.CodeValidationFailed,
+				Message:   "request validation failed",
+				Details:   map[string]string{"size": "must be an integer"},
+				RequestID: "req-1",
+			},
+		},
+		{
+			"GET",
+			"/offers",
+			"",
+			http.StatusInternalServerError,
+			api.ErrorResponse{
+				Code:      api.CodeInternal,
+				Message:   "Internal Server Error",
+				RequestID: "req-1",
+			},
+		},
+		{
+			"GET",
+			"/unknown",
+			"",
+			http.StatusNotFound,
+			api.ErrorResponse{
+				Code:      api.CodeNotFound,
+				Message:   errRouteNotFound.Error(),
+				RequestID: "req-1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %s", test.method, test.path), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			req := httptest.NewRequest(test.method, test.path, bytes.NewBufferString(test.body))
+			req.Header.Set("X-Request-ID", "req-1")
+
+			sut.ServeHTTP(w, req)
+
+			var resp api.ErrorResponse
+			assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+			assert.Equal(t, test.expectedResp, resp)
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	sut := SetupRouteHandlers(&RouteHandlers{
+		GetOffer:    &testGetOffer{getOfferErr: storage.ErrNotFound},
+		Metrics:     registry,
+		MetricsPath: "/metrics",
+	}, zaptest.NewLogger(t).Sugar())
+
+	for _, path := range []string{
+		"/offers/eca51142-3bf0-4766-baf7-2a168c964024",
+		"/offers/cd8a6d49-10f2-46d4-9447-0887091e9f2e",
+		"/unknown",
+	} {
+		sut.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	sut.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `offers_http_requests_total{method="GET",route="/offers/:offerID",status="404"} 2`)
+	assert.Contains(t, w.Body.String(), `offers_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, w.Body.String(), `offers_http_request_duration_seconds_count{method="GET",route="/offers/:offerID",status="404"} 2`)
+}
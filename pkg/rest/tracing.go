@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("example.com/playground/pkg/rest")
+
+// tracingMiddleware continues the trace from an incoming W3C traceparent
+// header, or starts a new one, and hands the span to handlers through the
+// request context.
+func tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("offers", route, c.Request)...),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// traceFields returns the ids of the span in c's request context as zap
+// key-value pairs so log lines can be joined with their trace.
+func traceFields(c *gin.Context) []interface{} {
+	sc := trace.SpanContextFromContext(c.Request.Context())
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []interface{}{
+		"trace_id", sc.TraceID().String(),
+		"span_id", sc.SpanID().String(),
+	}
+}
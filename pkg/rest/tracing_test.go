@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	}()
+
+	core, logs := observer.New(zap.InfoLevel)
+
+	gin.SetMode(gin.TestMode)
+	r := SetupRouteHandlers(&RouteHandlers{GetOffer: &testGetOffer{}}, zap.New(core).Sugar())
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpanID = "00f067aa0ba902b7"
+	)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/offers/d8e4d4b5-ec5c-4d36-9d38-1ad6b7b4e1a4", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpanID+"-01")
+	r.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "GET /offers/:offerID", span.Name())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, parentSpanID, span.Parent().SpanID().String())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	entries := logs.FilterMessage("request").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, traceID, entries[0].ContextMap()["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), entries[0].ContextMap()["span_id"])
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/offers/d8e4d4b5-ec5c-4d36-9d38-1ad6b7b4e1a4", nil))
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.False(t, spans[1].Parent().IsValid())
+	assert.NotEqual(t, traceID, spans[1].SpanContext().TraceID().String())
+
+	getOffer := &testGetOffer{getOfferErr: assert.AnError}
+	r = SetupRouteHandlers(&RouteHandlers{GetOffer: getOffer}, zap.NewNop().Sugar())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/offers/d8e4d4b5-ec5c-4d36-9d38-1ad6b7b4e1a4", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	spans = recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
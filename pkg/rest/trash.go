@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"example.com/playground/pkg/offers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-ozzo/ozzo-validation/is"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// getTrash lists deleted offers with the same paging and filters as getAll,
+// each carrying its deleted_at.
+func getTrash(g offers.GetTrash) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		page, err := parsePage(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		filter, err := parseOffersFilter(c)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, err)
+
+			return
+		}
+
+		resp, err := g.GetTrash(c.Request.Context(), page, filter)
+		if err != nil {
+			abortWithError(c, storageErrorStatus(err), err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func restore(r offers.RestoreOffer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer c.Header("Content-Type", "application/json")
+
+		offerID := c.Param("offerID")
+
+		if err := validation.Validate(offerID,
+			validation.Required,
+			is.UUID,
+		); err != nil {
+			abortWithError(c, http.StatusUnprocessableEntity, validation.Errors{"offerID": err})
+
+			return
+		}
+
+		resp, err := r.Restore(c.Request.Context(), offerID)
+		if err != nil {
+			abortWithError(c, storageErrorStatus(err), err)
+
+			return
+		}
+
+		setETag(c, resp)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// parsePurge tells whether a delete asks for the offer to be purged.
+func parsePurge(c *gin.Context) (bool, error) {
+	v, ok := c.GetQuery("purge")
+	if !ok {
+		return false, nil
+	}
+
+	purge, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, validation.Errors{"purge": errNotBoolean}
+	}
+
+	return purge, nil
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/offers"
+	"example.com/playground/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testGetTrash struct {
+	getTrashCalled int
+	getTrashErr    error
+	filter         *api.OffersFilter
+}
+
+func (t *testGetTrash) GetTrash(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*api.JobOffersPaginationResponse, error) {
+	t.getTrashCalled++
+	t.filter = filter
+	return &api.JobOffersPaginationResponse{Data: []api.JobOfferResponse{}}, t.getTrashErr
+}
+
+func TestGetTrash(t *testing.T) {
+	tests := []struct {
+		query          string
+		expectedCalls  int
+		expectedStatus int
+		expectedErr    error
+	}{
+		{"", 1, http.StatusOK, nil},
+		{"company=ACME&size=5", 1, http.StatusOK, nil},
+		{"size=ten", 0, http.StatusBadRequest, nil},
+		{"min_salary=lots", 0, http.StatusBadRequest, nil},
+		{"", 1, http.StatusInternalServerError, errors.New("oops..something went wrong")},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			g := testGetTrash{
+				getTrashErr: test.expectedErr,
+			}
+
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = httptest.NewRequest("GET", "/offers/trash?"+test.query, nil)
+
+			getTrash(&g)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedCalls, g.getTrashCalled)
+		})
+	}
+}
+
+type testRestoreOffer struct {
+	restoreOfferCalled int
+	restoreOfferErr    error
+}
+
+func (r *testRestoreOffer) Restore(ctx context.Context, offerID string) (*api.JobOfferResponse, error) {
+	r.restoreOfferCalled++
+	if r.restoreOfferErr != nil {
+		return nil, r.restoreOfferErr
+	}
+
+	return &api.JobOfferResponse{ID: offerID, Version: 2}, nil
+}
+
+func TestRestoreOffer(t *testing.T) {
+	tests := []struct {
+		offerID        string
+		expectedCalls  int
+		expectedStatus int
+		expectedErr    error
+	}{
+		{"eca51142-3bf0-4766-baf7-2a168c964024", 1, http.StatusOK, nil},
+		{"eca51142-3bf0-4766-baf7-2a168c964024", 1, http.StatusNotFound, storage.ErrNotFound},
+		{"eca51142-3bf0-4766-baf7-2a168c964024", 1, http.StatusForbidden, offers.ErrForbidden},
+		{"eca51142-3bf0-4766-baf7-2a168c964024", 1, http.StatusConflict, offers.ErrExpired},
+		{"eca51142-3bf0-4766-baf7-2a168c964024", 1, http.StatusInternalServerError, errors.New("oops..something went wrong")},
+		{"test", 0, http.StatusUnprocessableEntity, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			r := testRestoreOffer{
+				restoreOfferErr: test.expectedErr,
+			}
+
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Params = []gin.Param{
+				{
+					Key:   "offerID",
+					Value: test.offerID,
+				},
+			}
+			ctx.Request = httptest.NewRequest("POST", fmt.Sprintf("/offers/%s/restore", test.offerID), nil)
+
+			restore(&r)(ctx)
+
+			assert.Equal(t, test.expectedStatus, ctx.Writer.Status())
+			assert.Equal(t, test.expectedCalls, r.restoreOfferCalled)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// createBatchSize caps the number of rows per INSERT statement.
+const createBatchSize = 100
+
+// ErrBatchFailed is returned for all-or-nothing batches in which at least
+// one item failed. Nothing has been written and the per-item errors tell
+// which items were at fault.
+var ErrBatchFailed = errors.New("batch failed, no changes were applied")
+
+func (s *dbRepository) CreateBatch(ctx context.Context, offers []*Offer, bestEffort bool) ([]error, error) {
+	ctx, end := instrument(ctx, "CreateBatch")
+	defer end()
+
+	errs := make([]error, len(offers))
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Insert everything in a few multi-row statements, and only when
+		// that fails go item by item to find out which ones are at fault.
+		err := tx.Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(compact(offers), createBatchSize).Error
+		})
+		if err != nil {
+			for i, offer := range offers {
+				if offer == nil {
+					continue
+				}
+
+				// A failed statement may have left ids behind on the offers
+				// it got to.
+				offer.ID = 0
+
+				if err := tx.Transaction(func(tx *gorm.DB) error {
+					return tx.Create(offer).Error
+				}); err != nil {
+					errs[i] = translateError(err)
+				}
+			}
+		}
+
+		return batchOutcome(errs, bestEffort)
+	})
+
+	return batchErrors(errs, err)
+}
+
+// compact drops the nil offers.
+func compact(offers []*Offer) []*Offer {
+	rows := make([]*Offer, 0, len(offers))
+
+	for _, offer := range offers {
+		if offer != nil {
+			rows = append(rows, offer)
+		}
+	}
+
+	return rows
+}
+
+func (s *dbRepository) UpdateBatch(ctx context.Context, offerIDs []string, changes []*Changes, checks []Check, bestEffort bool) ([]*Offer, []error, error) {
+	ctx, end := instrument(ctx, "UpdateBatch")
+	defer end()
+
+	offers := make([]*Offer, len(offerIDs))
+	errs := make([]error, len(offerIDs))
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, offerID := range offerIDs {
+			if changes[i] == nil {
+				continue
+			}
+
+			var offer Offer
+
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				if err := lockOffer(tx, offerID, checks[i], &offer); err != nil {
+					return err
+				}
+
+				columns := changes[i].columns()
+				if len(columns) == 0 {
+					return nil
+				}
+
+				return updateOffer(tx, &offer, columns)
+			})
+			if err != nil {
+				errs[i] = translateError(err)
+
+				continue
+			}
+
+			offers[i] = &offer
+		}
+
+		return batchOutcome(errs, bestEffort)
+	})
+
+	errs, err = batchErrors(errs, err)
+	if err != nil {
+		return nil, errs, err
+	}
+
+	return offers, errs, nil
+}
+
+func (s *dbRepository) DeleteBatch(ctx context.Context, offerIDs []string, checks []Check, bestEffort bool) ([]error, error) {
+	ctx, end := instrument(ctx, "DeleteBatch")
+	defer end()
+
+	errs := make([]error, len(offerIDs))
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, offerID := range offerIDs {
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				var offer Offer
+				if err := lockOffer(tx, offerID, checks[i], &offer); err != nil {
+					return err
+				}
+
+				return tx.Delete(&offer).Error
+			})
+
+			errs[i] = translateError(err)
+		}
+
+		return batchOutcome(errs, bestEffort)
+	})
+
+	return batchErrors(errs, err)
+}
+
+// batchOutcome rolls an all-or-nothing batch back when any item failed.
+func batchOutcome(errs []error, bestEffort bool) error {
+	if bestEffort {
+		return nil
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return ErrBatchFailed
+		}
+	}
+
+	return nil
+}
+
+func batchErrors(errs []error, err error) ([]error, error) {
+	switch {
+	case errors.Is(err, ErrBatchFailed):
+		return errs, err
+	case err != nil:
+		return nil, translateError(err)
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,679 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"example.com/playground/pkg/api"
+	"example.com/playground/pkg/migrations"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testCursorKey = []byte("conformance")
+
+// Every backend has to pass conformance, so that the server behaves the
+// same whichever one it runs on.
+
+func TestMemoryConformance(t *testing.T) {
+	conformance(t, func(t *testing.T) OfferRepository {
+		return NewMemoryRepository(testCursorKey)
+	})
+}
+
+// Every test gets a database file of its own.
+func TestSQLiteConformance(t *testing.T) {
+	conformance(t, func(t *testing.T) OfferRepository {
+		db, err := SetupSQLite(&SQLiteConfig{Path: filepath.Join(t.TempDir(), "offers.db")}, zap.NewNop().Sugar())
+		require.Nil(t, err)
+
+		migrate(t, db)
+
+		return NewRepository(db, testCursorKey)
+	})
+}
+
+func migrate(t *testing.T, db *gorm.DB) {
+	m, err := migrations.NewMigrator(db)
+	require.Nil(t, err)
+
+	_, err = m.Up(context.Background())
+	require.Nil(t, err)
+}
+
+// TestPostgresConformance runs against the database in POSTGRES_TEST_DSN,
+// e.g. "host=localhost user=postgres password=your_password dbname=offers_test sslmode=disable".
+// The job_offers table is emptied before every test.
+func TestPostgresConformance(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" || testing.Short() {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, DriverName: "postgres"}), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: utcNow,
+	})
+	require.Nil(t, err)
+
+	migrate(t, db)
+
+	conformance(t, func(t *testing.T) OfferRepository {
+		require.Nil(t, db.Exec("TRUNCATE TABLE job_offers RESTART IDENTITY").Error)
+
+		return NewRepository(db, testCursorKey)
+	})
+}
+
+func conformance(t *testing.T, newRepository func(t *testing.T) OfferRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r OfferRepository)
+	}{
+		{"CreateGet", testCreateGet},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"Check", testCheck},
+		{"Sorting", testSorting},
+		{"OffsetPagination", testOffsetPagination},
+		{"CursorPagination", testCursorPagination},
+		{"Filters", testFilters},
+		{"Expire", testExpire},
+		{"Each", testEach},
+		{"Batch", testBatch},
+		{"Trash", testTrash},
+		{"PurgeDeleted", testPurgeDeleted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newRepository(t))
+		})
+	}
+}
+
+const missingID = "eca51142-3bf0-4766-baf7-2a168c964024"
+
+var errRejected = errors.New("rejected")
+
+func allow(*Offer) error { return nil }
+
+func reject(*Offer) error { return errRejected }
+
+func date(year int, month time.Month, day int) null.Time {
+	return null.TimeFrom(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+func createOffer(t *testing.T, r OfferRepository, offer *Offer) *Offer {
+	require.Nil(t, r.Create(context.Background(), offer))
+
+	return offer
+}
+
+func testCreateGet(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	created := createOffer(t, r, &Offer{
+		Company:        "ACME",
+		Email:          "hr@acme.com",
+		ExpirationDate: date(2100, 3, 1),
+		LinkToOffer:    null.StringFrom("http://acme.com/jobs"),
+		Details:        null.StringFrom("Go, SQL"),
+		Salary:         5000.5,
+		Phone:          "+38978653534",
+		OwnerID:        null.StringFrom("alice"),
+		Version:        1,
+	})
+	assert.NotEqual(t, uuid.Nil, created.UUID)
+	assert.NotZero(t, created.ID)
+
+	offer, err := r.Get(ctx, created.UUID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, created.UUID, offer.UUID)
+	assert.Equal(t, "ACME", offer.Company)
+	assert.Equal(t, "hr@acme.com", offer.Email)
+	assert.True(t, time.Date(2100, 3, 1, 0, 0, 0, 0, time.UTC).Equal(offer.ExpirationDate.Time))
+	assert.Equal(t, null.StringFrom("http://acme.com/jobs"), offer.LinkToOffer)
+	assert.Equal(t, null.StringFrom("Go, SQL"), offer.Details)
+	assert.Equal(t, 5000.5, offer.Salary)
+	assert.Equal(t, "+38978653534", offer.Phone)
+	assert.Equal(t, null.StringFrom("alice"), offer.OwnerID)
+	assert.Equal(t, int64(1), offer.Version)
+
+	other := createOffer(t, r, &Offer{Company: "TEST", Version: 1})
+	assert.NotEqual(t, created.UUID, other.UUID)
+
+	err = r.Create(ctx, &Offer{UUID: created.UUID, Company: "TEST", Version: 1})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	offer, err = r.Get(ctx, other.UUID.String())
+	assert.Nil(t, err)
+	assert.False(t, offer.ExpirationDate.Valid)
+	assert.False(t, offer.Details.Valid)
+
+	_, err = r.Get(ctx, missingID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func testUpdate(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	created := createOffer(t, r, &Offer{Company: "ACME", Email: "hr@acme.com", Details: null.StringFrom("Go"), ExpirationDate: date(2100, 3, 1), Version: 1})
+	company, salary := "ACME Corp", 100.0
+
+	updated, err := r.Update(ctx, created.UUID.String(), &Changes{
+		Company:        &company,
+		Salary:         &salary,
+		Details:        &null.String{},
+		ExpirationDate: &null.Time{},
+	}, allow)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+	assert.Equal(t, "ACME Corp", updated.Company)
+	assert.Equal(t, 100.0, updated.Salary)
+	assert.False(t, updated.Details.Valid)
+	assert.False(t, updated.ExpirationDate.Valid)
+	// Fields without changes stay as they are.
+	assert.Equal(t, "hr@acme.com", updated.Email)
+
+	// Empty changes leave the version alone.
+	updated, err = r.Update(ctx, created.UUID.String(), &Changes{}, allow)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+	assert.Equal(t, "ACME Corp", updated.Company)
+
+	offer, err := r.Get(ctx, created.UUID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "ACME Corp", offer.Company)
+	assert.Equal(t, int64(2), offer.Version)
+
+	_, err = r.Update(ctx, missingID, &Changes{Company: &company}, allow)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func testDelete(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	created := createOffer(t, r, &Offer{Company: "ACME", Version: 1})
+
+	assert.Nil(t, r.Delete(ctx, created.UUID.String(), allow))
+
+	_, err := r.Get(ctx, created.UUID.String())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, r.Delete(ctx, created.UUID.String(), allow), ErrNotFound)
+
+	page, err := r.List(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Offers)
+	assert.Equal(t, int64(0), *page.TotalCount)
+}
+
+// testCheck makes sure a check sees the offer as stored and that failing it
+// changes nothing.
+func testCheck(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	created := createOffer(t, r, &Offer{Company: "ACME", OwnerID: null.StringFrom("alice"), Version: 1})
+	company := "Mine now"
+
+	var checked *Offer
+
+	_, err := r.Update(ctx, created.UUID.String(), &Changes{Company: &company}, func(o *Offer) error {
+		checked = o
+
+		return errRejected
+	})
+	assert.ErrorIs(t, err, errRejected)
+	require.NotNil(t, checked)
+	assert.Equal(t, created.UUID, checked.UUID)
+	assert.Equal(t, null.StringFrom("alice"), checked.OwnerID)
+	assert.Equal(t, int64(1), checked.Version)
+
+	assert.ErrorIs(t, r.Delete(ctx, created.UUID.String(), reject), errRejected)
+
+	errs, err := r.DeleteBatch(ctx, []string{created.UUID.String()}, []Check{reject}, true)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, errs[0], errRejected)
+
+	offer, err := r.Get(ctx, created.UUID.String())
+	assert.Nil(t, err)
+	assert.Equal(t, "ACME", offer.Company)
+	assert.Equal(t, int64(1), offer.Version)
+}
+
+// createOffers stores offers whose companies, salaries and details differ
+// so that every sort key orders them differently.
+func createOffers(t *testing.T, r OfferRepository) []*Offer {
+	offers := []*Offer{
+		{Company: "Delta", Salary: 300, Details: null.StringFrom("golang")},
+		{Company: "alpha", Salary: 100, ExpirationDate: date(2100, 1, 1)},
+		{Company: "Charlie", Salary: 300, Details: null.StringFrom("java"), OwnerID: null.StringFrom("alice")},
+		{Company: "Bravo", Salary: 500, ExpirationDate: date(2090, 1, 1), Details: null.StringFrom("golang and sql")},
+		{Company: "Echo", Salary: 200},
+	}
+
+	for _, offer := range offers {
+		offer.Version = 1
+		createOffer(t, r, offer)
+	}
+
+	return offers
+}
+
+func ids(offers []Offer) []string {
+	ids := make([]string, 0, len(offers))
+	for _, o := range offers {
+		ids = append(ids, o.UUID.String())
+	}
+
+	return ids
+}
+
+func uuids(offers ...*Offer) []string {
+	ids := make([]string, 0, len(offers))
+	for _, o := range offers {
+		ids = append(ids, o.UUID.String())
+	}
+
+	return ids
+}
+
+func testSorting(t *testing.T, r OfferRepository) {
+	o := createOffers(t, r)
+
+	tests := []struct {
+		sort     []api.SortKey
+		expected []*Offer
+	}{
+		{nil, o},
+		{[]api.SortKey{{Field: "id", Descending: true}}, []*Offer{o[4], o[3], o[2], o[1], o[0]}},
+		{[]api.SortKey{{Field: "salary"}}, []*Offer{o[1], o[4], o[0], o[2], o[3]}},
+		{[]api.SortKey{{Field: "salary", Descending: true}}, []*Offer{o[3], o[0], o[2], o[4], o[1]}},
+		{[]api.SortKey{{Field: "salary", Descending: true}, {Field: "company"}}, []*Offer{o[3], o[2], o[0], o[4], o[1]}},
+		// Offers without an expiration date come last.
+		{[]api.SortKey{{Field: "expiration_date"}}, []*Offer{o[3], o[1], o[0], o[2], o[4]}},
+		// Ties are broken by ascending id, whatever the direction.
+		{[]api.SortKey{{Field: "details", Descending: true}}, []*Offer{o[2], o[3], o[0], o[1], o[4]}},
+		{[]api.SortKey{{Field: "created_at", Descending: true}}, []*Offer{o[4], o[3], o[2], o[1], o[0]}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%v", test.sort), func(t *testing.T) {
+			page, err := r.List(context.Background(), &api.PageRequest{Size: 10, Sort: test.sort}, &api.OffersFilter{})
+			assert.Nil(t, err)
+			assert.Equal(t, uuids(test.expected...), ids(page.Offers))
+		})
+	}
+}
+
+func testOffsetPagination(t *testing.T, r OfferRepository) {
+	o := createOffers(t, r)
+	sort := []api.SortKey{{Field: "salary"}}
+
+	page, err := r.List(context.Background(), &api.PageRequest{Size: 2, Offset: 2, Sort: sort}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[0], o[2]), ids(page.Offers))
+	assert.Equal(t, int64(5), *page.TotalCount)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NotEmpty(t, page.PrevCursor)
+
+	page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Offset: 4, Sort: sort}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[3]), ids(page.Offers))
+	assert.Empty(t, page.NextCursor)
+
+	page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Offset: 10, Sort: sort}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Offers)
+	assert.Equal(t, int64(5), *page.TotalCount)
+}
+
+func testCursorPagination(t *testing.T, r OfferRepository) {
+	o := createOffers(t, r)
+	sort := []api.SortKey{{Field: "salary", Descending: true}, {Field: "expiration_date"}}
+	expected := uuids(o[3], o[0], o[2], o[4], o[1])
+
+	var pages [][]string
+	var cursors []string
+
+	page, err := r.List(context.Background(), &api.PageRequest{Size: 2, Sort: sort}, &api.OffersFilter{})
+	require.Nil(t, err)
+	assert.Empty(t, page.PrevCursor)
+
+	for {
+		pages = append(pages, ids(page.Offers))
+		cursors = append(cursors, page.PrevCursor)
+
+		if page.NextCursor == "" {
+			break
+		}
+
+		page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Sort: sort, Cursor: page.NextCursor}, &api.OffersFilter{})
+		require.Nil(t, err)
+		assert.Nil(t, page.TotalCount)
+	}
+
+	assert.Equal(t, [][]string{expected[0:2], expected[2:4], expected[4:5]}, pages)
+
+	// Walking back from the last page gives the same pages.
+	page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Sort: sort, Cursor: cursors[2]}, &api.OffersFilter{})
+	require.Nil(t, err)
+	assert.Equal(t, expected[2:4], ids(page.Offers))
+
+	page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Sort: sort, Cursor: page.PrevCursor}, &api.OffersFilter{})
+	require.Nil(t, err)
+	assert.Equal(t, expected[0:2], ids(page.Offers))
+	assert.Empty(t, page.PrevCursor)
+
+	// Offers added in the meantime don't shift the pages around a cursor.
+	createOffer(t, r, &Offer{Company: "Foxtrot", Salary: 1000, Version: 1})
+
+	page, err = r.List(context.Background(), &api.PageRequest{Size: 2, Sort: sort, Cursor: cursors[1]}, &api.OffersFilter{})
+	require.Nil(t, err)
+	assert.Equal(t, expected[0:2], ids(page.Offers))
+
+	_, err = r.List(context.Background(), &api.PageRequest{Size: 2, Cursor: cursors[1]}, &api.OffersFilter{})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func testFilters(t *testing.T, r OfferRepository) {
+	o := createOffers(t, r)
+	expired := createOffer(t, r, &Offer{Company: "Golf", Salary: 300, ExpirationDate: date(2000, 1, 1), Version: 1})
+
+	min, max := 200.0, 300.0
+	after := time.Date(2095, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2095, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		filter   *api.OffersFilter
+		expected []*Offer
+	}{
+		{&api.OffersFilter{}, o},
+		{&api.OffersFilter{IncludeExpired: true}, append(append([]*Offer{}, o...), expired)},
+		{&api.OffersFilter{Company: "ALPHA"}, []*Offer{o[1]}},
+		{&api.OffersFilter{Owner: "alice"}, []*Offer{o[2]}},
+		{&api.OffersFilter{MinSalary: &min}, []*Offer{o[0], o[2], o[3], o[4]}},
+		{&api.OffersFilter{MinSalary: &min, MaxSalary: &max}, []*Offer{o[0], o[2], o[4]}},
+		{&api.OffersFilter{MinSalary: &min, MaxSalary: &max, IncludeExpired: true}, []*Offer{o[0], o[2], o[4], expired}},
+		{&api.OffersFilter{ExpiresAfter: &after}, []*Offer{o[1]}},
+		{&api.OffersFilter{ExpiresBefore: &before}, []*Offer{o[3]}},
+		{&api.OffersFilter{Query: "golang"}, []*Offer{o[0], o[3]}},
+		{&api.OffersFilter{Query: "Golang SQL"}, []*Offer{o[3]}},
+		{&api.OffersFilter{Query: "charlie"}, []*Offer{o[2]}},
+		// Queries match whole words only.
+		{&api.OffersFilter{Query: "go"}, []*Offer{}},
+		{&api.OffersFilter{Query: "sql, GOLANG!"}, []*Offer{o[3]}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%+v", *test.filter), func(t *testing.T) {
+			page, err := r.List(context.Background(), &api.PageRequest{Size: 10}, test.filter)
+			assert.Nil(t, err)
+			assert.Equal(t, uuids(test.expected...), ids(page.Offers))
+			assert.Equal(t, int64(len(test.expected)), *page.TotalCount)
+		})
+	}
+
+	// Changes are searchable right away.
+	details := null.StringFrom("go and rust")
+	_, err := r.Update(context.Background(), o[1].UUID.String(), &Changes{Details: &details}, allow)
+	require.Nil(t, err)
+
+	page, err := r.List(context.Background(), &api.PageRequest{Size: 10}, &api.OffersFilter{Query: "go"})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[1]), ids(page.Offers))
+}
+
+func testExpire(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	var expired []*Offer
+	for i := 0; i < 3; i++ {
+		expired = append(expired, createOffer(t, r, &Offer{Company: "ACME", ExpirationDate: date(2000, 1, 1), Version: 1}))
+	}
+
+	current := createOffer(t, r, &Offer{Company: "ACME", ExpirationDate: date(2100, 1, 1), Version: 1})
+
+	unlock, err := r.LockExpiry(ctx)
+	require.Nil(t, err)
+	defer unlock()
+
+	swept, err := r.Expire(ctx, time.Now(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), swept)
+
+	swept, err = r.Expire(ctx, time.Now(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), swept)
+
+	for _, o := range expired {
+		_, err := r.Get(ctx, o.UUID.String())
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+
+	_, err = r.Get(ctx, current.UUID.String())
+	assert.Nil(t, err)
+}
+
+func testEach(t *testing.T, r OfferRepository) {
+	o := createOffers(t, r)
+
+	var seen []string
+	collect := func(offer *Offer) error {
+		seen = append(seen, offer.UUID.String())
+
+		return nil
+	}
+
+	assert.Nil(t, r.Each(context.Background(), &api.OffersFilter{MinSalary: &o[3].Salary}, collect))
+	assert.Equal(t, uuids(o[3]), seen)
+
+	seen = nil
+	assert.Nil(t, r.Each(context.Background(), &api.OffersFilter{}, collect))
+	assert.Equal(t, uuids(o...), seen)
+
+	// Returning an error stops it.
+	seen = nil
+	err := r.Each(context.Background(), &api.OffersFilter{}, func(offer *Offer) error {
+		seen = append(seen, offer.UUID.String())
+
+		return errRejected
+	})
+	assert.ErrorIs(t, err, errRejected)
+	assert.Equal(t, uuids(o[0]), seen)
+}
+
+func testBatch(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	taken := createOffer(t, r, &Offer{Company: "ACME", Version: 1})
+
+	// The last offer can't be stored, its uuid is taken.
+	batch := func() []*Offer {
+		return []*Offer{{Company: "TEST", Version: 1}, nil, {UUID: taken.UUID, Company: "TEST", Version: 1}}
+	}
+
+	errs, err := r.CreateBatch(ctx, batch(), false)
+	assert.ErrorIs(t, err, ErrBatchFailed)
+	assert.Nil(t, errs[0])
+	assert.Nil(t, errs[1])
+	assert.ErrorIs(t, errs[2], ErrConflict)
+
+	page, err := r.List(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(taken), ids(page.Offers))
+
+	offers := batch()
+
+	errs, err = r.CreateBatch(ctx, offers, true)
+	assert.Nil(t, err)
+	assert.Nil(t, errs[0])
+	assert.ErrorIs(t, errs[2], ErrConflict)
+
+	created := offers[0].UUID.String()
+
+	_, err = r.Get(ctx, created)
+	assert.Nil(t, err)
+
+	company := "ACME"
+	changes := []*Changes{{Company: &company}, nil, {Company: &company}}
+
+	updated, errs, err := r.UpdateBatch(ctx, []string{created, taken.UUID.String(), missingID}, changes, []Check{allow, allow, allow}, false)
+	assert.ErrorIs(t, err, ErrBatchFailed)
+	assert.Nil(t, updated)
+	assert.Nil(t, errs[0])
+	assert.Nil(t, errs[1])
+	assert.ErrorIs(t, errs[2], ErrNotFound)
+
+	offer, err := r.Get(ctx, created)
+	assert.Nil(t, err)
+	assert.Equal(t, "TEST", offer.Company)
+	assert.Equal(t, int64(1), offer.Version)
+
+	updated, errs, err = r.UpdateBatch(ctx, []string{created, taken.UUID.String(), missingID}, changes, []Check{allow, allow, allow}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "ACME", updated[0].Company)
+	assert.Equal(t, int64(2), updated[0].Version)
+	assert.Nil(t, updated[1])
+	assert.Nil(t, updated[2])
+	assert.ErrorIs(t, errs[2], ErrNotFound)
+
+	offer, err = r.Get(ctx, created)
+	assert.Nil(t, err)
+	assert.Equal(t, updated[0], offer)
+
+	// Each item is vetted by its own check.
+	updated, errs, err = r.UpdateBatch(ctx, []string{created, created}, []*Changes{{Company: &company}, {Company: &company}}, []Check{reject, allow}, true)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, errs[0], errRejected)
+	assert.Nil(t, errs[1])
+	assert.Equal(t, int64(3), updated[1].Version)
+
+	errs, err = r.DeleteBatch(ctx, []string{created, missingID}, []Check{allow, allow}, false)
+	assert.ErrorIs(t, err, ErrBatchFailed)
+	assert.Nil(t, errs[0])
+	assert.ErrorIs(t, errs[1], ErrNotFound)
+
+	_, err = r.Get(ctx, created)
+	assert.Nil(t, err)
+
+	errs, err = r.DeleteBatch(ctx, []string{created, missingID}, []Check{allow, allow}, true)
+	assert.Nil(t, err)
+	assert.Nil(t, errs[0])
+
+	_, err = r.Get(ctx, created)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func testTrash(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+	o := createOffers(t, r)
+
+	require.Nil(t, r.Delete(ctx, o[0].UUID.String(), allow))
+	require.Nil(t, r.Delete(ctx, o[2].UUID.String(), allow))
+
+	page, err := r.ListDeleted(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[0], o[2]), ids(page.Offers))
+	assert.Equal(t, int64(2), *page.TotalCount)
+
+	for _, offer := range page.Offers {
+		assert.True(t, offer.DeletedAt.Valid)
+	}
+
+	page, err = r.ListDeleted(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{Owner: "alice"})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[2]), ids(page.Offers))
+
+	page, err = r.List(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[1], o[3], o[4]), ids(page.Offers))
+
+	_, err = r.Restore(ctx, o[0].UUID.String(), reject)
+	assert.ErrorIs(t, err, errRejected)
+
+	restored, err := r.Restore(ctx, o[0].UUID.String(), allow)
+	assert.Nil(t, err)
+	assert.Equal(t, o[0].UUID, restored.UUID)
+	assert.Equal(t, int64(2), restored.Version)
+	assert.False(t, restored.DeletedAt.Valid)
+
+	_, err = r.Get(ctx, o[0].UUID.String())
+	assert.Nil(t, err)
+
+	// Only offers in the trash can be restored.
+	_, err = r.Restore(ctx, o[0].UUID.String(), allow)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = r.Restore(ctx, missingID, allow)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Purging works on offers in the trash and out of it.
+	assert.Nil(t, r.Purge(ctx, o[2].UUID.String(), allow))
+
+	_, err = r.Restore(ctx, o[2].UUID.String(), allow)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, r.Purge(ctx, o[4].UUID.String(), reject), errRejected)
+	assert.Nil(t, r.Purge(ctx, o[4].UUID.String(), allow))
+
+	_, err = r.Get(ctx, o[4].UUID.String())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, r.Purge(ctx, o[4].UUID.String(), allow), ErrNotFound)
+
+	page, err = r.ListDeleted(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Offers)
+
+	page, err = r.List(ctx, &api.PageRequest{Size: 10}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, uuids(o[0], o[1], o[3]), ids(page.Offers))
+}
+
+func testPurgeDeleted(t *testing.T, r OfferRepository) {
+	ctx := context.Background()
+
+	var trashed []*Offer
+	for i := 0; i < 3; i++ {
+		offer := createOffer(t, r, &Offer{Company: "ACME", Version: 1})
+		require.Nil(t, r.Delete(ctx, offer.UUID.String(), allow))
+
+		trashed = append(trashed, offer)
+	}
+
+	current := createOffer(t, r, &Offer{Company: "ACME", Version: 1})
+
+	purged, err := r.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = r.PurgeDeleted(ctx, time.Now(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	purged, err = r.PurgeDeleted(ctx, time.Now(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	for _, o := range trashed {
+		_, err := r.Restore(ctx, o.UUID.String(), allow)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+
+	_, err = r.Get(ctx, current.UUID.String())
+	assert.Nil(t, err)
+}
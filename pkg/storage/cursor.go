@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// cursor marks a position in a keyset-paginated listing: the values of
+// every sort key of the row next to which the following page starts.
+type cursor struct {
+	Sort     string        `json:"s"`
+	Values   []interface{} `json:"v"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+// encodeCursor serialises c into an opaque token signed with key so that
+// clients can't forge positions or tamper with the sort keys.
+func encodeCursor(key []byte, c cursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(sign(key, payload)), nil
+}
+
+func decodeCursor(key []byte, token string) (*cursor, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if !hmac.Equal(signature, sign(key, payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+func sign(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	return mac.Sum(nil)
+}
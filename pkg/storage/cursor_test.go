@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	key := []byte("secret")
+
+	token, err := encodeCursor(key, cursor{Sort: "-salary,company", Values: []interface{}{18000.5, "ACME", 42}, Backward: true})
+	assert.Nil(t, err)
+
+	c, err := decodeCursor(key, token)
+	assert.Nil(t, err)
+	assert.Equal(t, &cursor{Sort: "-salary,company", Values: []interface{}{json.Number("18000.5"), "ACME", json.Number("42")}, Backward: true}, c)
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	token, err := encodeCursor([]byte("secret"), cursor{Sort: "company", Values: []interface{}{"ACME", 7}})
+	assert.Nil(t, err)
+
+	forged, err := encodeCursor([]byte("guess"), cursor{Sort: "company", Values: []interface{}{"ACME", 1}})
+	assert.Nil(t, err)
+
+	payload := strings.Split(forged, ".")[0]
+	signature := strings.Split(token, ".")[1]
+
+	for _, tampered := range []string{
+		"",
+		"garbage",
+		forged,
+		payload + "." + signature,
+		token + ".extra",
+	} {
+		_, err := decodeCursor([]byte("secret"), tampered)
+		assert.Equal(t, ErrInvalidCursor, err, tampered)
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotFound = errors.New("offer not found")
+	ErrConflict = errors.New("offer conflicts with an existing one")
+
+	ErrInvalidCursor = errors.New("invalid or expired cursor")
+	ErrLocked        = errors.New("lock is held by another runner")
+)
+
+const uniqueViolation = "23505"
+
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrConflict
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey) {
+		return ErrConflict
+	}
+
+	return err
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+
+	"example.com/playground/pkg/api"
+)
+
+// exportChunkSize is the number of offers Each reads per query.
+const exportChunkSize = 500
+
+// exportSort walks the offers in the order they were created.
+var exportSort = []api.SortKey{{Field: "id"}}
+
+// Each reads offers chunk by chunk, seeking past the last one seen.
+func (s *dbRepository) Each(ctx context.Context, filter *api.OffersFilter, fn func(*Offer) error) error {
+	ctx, end := instrument(ctx, "Export")
+	defer end()
+
+	page := &api.PageRequest{Size: exportChunkSize, Sort: exportSort}
+
+	var position *cursor
+
+	for {
+		var offers []Offer
+
+		if err := s.db.WithContext(ctx).
+			Scopes(filterOffers(filter), seekOffers(page, position)).
+			Limit(page.Size).
+			Find(&offers).
+			Error; err != nil {
+
+			return err
+		}
+
+		for i := range offers {
+			if err := fn(&offers[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(offers) < page.Size {
+			return nil
+		}
+
+		next := cursorAt(offers[len(offers)-1], page.Sort, false)
+		position = &next
+	}
+}
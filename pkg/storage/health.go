@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+
+	"example.com/playground/pkg/api"
+
+	"gorm.io/gorm"
+)
+
+type HealthCheck interface {
+	Check(context.Context) (interface{}, error)
+}
+
+type dbHealthCheck struct {
+	db *gorm.DB
+}
+
+// Check pings the database and reports connection pool statistics, which
+// are returned even when the ping fails to help diagnose exhausted pools.
+func (h *dbHealthCheck) Check(ctx context.Context) (interface{}, error) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	err = sqlDB.PingContext(ctx)
+
+	stats := sqlDB.Stats()
+
+	return &api.PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+	}, err
+}
+
+func NewHealthCheckService(db *gorm.DB) HealthCheck { return &dbHealthCheck{db: db} }
@@ -0,0 +1,658 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryRepository keeps offers in memory, for local development and tests.
+// It behaves like dbRepository, down to the order of listings and the
+// cursors it hands out, with two exceptions: strings sort bytewise as in
+// the C collation, and full-text search matches whole words without
+// stemming. Offers are copied on the way in and out.
+type memoryRepository struct {
+	mu sync.RWMutex
+
+	// offers holds every offer in id order, the ones in the trash
+	// included.
+	offers []*Offer
+	byUUID map[uuid.UUID]*Offer
+	lastID uint
+
+	cursorKey []byte
+}
+
+// NewMemoryRepository returns a repository that keeps offers in memory
+// only, so they are gone once the process exits.
+func NewMemoryRepository(cursorKey []byte) OfferRepository {
+	return &memoryRepository{
+		byUUID:    make(map[uuid.UUID]*Offer),
+		cursorKey: cursorKey,
+	}
+}
+
+func (s *memoryRepository) Create(ctx context.Context, offer *Offer) error {
+	_, end := instrument(ctx, "Create")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.insert(offer)
+}
+
+// insert fills in what the database would generate for a new offer and
+// stores a copy of it. The caller holds the write lock.
+func (s *memoryRepository) insert(offer *Offer) error {
+	if err := offer.BeforeCreate(nil); err != nil {
+		return err
+	}
+
+	if _, ok := s.byUUID[offer.UUID]; ok {
+		return ErrConflict
+	}
+
+	now := time.Now()
+
+	s.lastID++
+
+	offer.ID = s.lastID
+	offer.CreatedAt = now
+	offer.UpdatedAt = now
+
+	stored := *offer
+
+	s.offers = append(s.offers, &stored)
+	s.byUUID[stored.UUID] = &stored
+
+	return nil
+}
+
+func (s *memoryRepository) Get(ctx context.Context, offerID string) (*Offer, error) {
+	_, end := instrument(ctx, "Get")
+	defer end()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offer, err := s.find(offerID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	found := *offer
+
+	return &found, nil
+}
+
+// find looks up an offer that is in the trash or not, as deleted says. The
+// caller holds a lock.
+func (s *memoryRepository) find(offerID string, deleted bool) (*Offer, error) {
+	offer, err := s.lookup(offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.DeletedAt.Valid != deleted {
+		return nil, ErrNotFound
+	}
+
+	return offer, nil
+}
+
+// lookup finds an offer wherever it is.
+func (s *memoryRepository) lookup(offerID string) (*Offer, error) {
+	id, err := uuid.FromString(offerID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	offer, ok := s.byUUID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return offer, nil
+}
+
+func (s *memoryRepository) List(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error) {
+	_, end := instrument(ctx, "GetAll")
+	defer end()
+
+	return s.list(page, filter, false)
+}
+
+// list pages through the offers that are in the trash or not, as deleted
+// says.
+func (s *memoryRepository) list(page *api.PageRequest, filter *api.OffersFilter, deleted bool) (*OfferPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UTC()
+
+	count := func() (int64, error) {
+		var n int64
+		for _, o := range s.offers {
+			if o.DeletedAt.Valid == deleted && matchesFilter(o, filter, now) {
+				n++
+			}
+		}
+
+		return n, nil
+	}
+
+	fetch := func(position *cursor, limit int) ([]Offer, error) {
+		keys := sortKeys(page.Sort)
+		backward := position != nil && position.Backward
+
+		if position != nil && len(position.Values) != len(keys) {
+			return nil, ErrInvalidCursor
+		}
+
+		var offers []Offer
+		for _, o := range s.offers {
+			if o.DeletedAt.Valid != deleted || !matchesFilter(o, filter, now) {
+				continue
+			}
+
+			if position != nil && compareKeys(sortValues(*o, keys), position.Values, keys, backward) <= 0 {
+				continue
+			}
+
+			offers = append(offers, *o)
+		}
+
+		sort.SliceStable(offers, func(i, j int) bool {
+			return compareKeys(sortValues(offers[i], keys), sortValues(offers[j], keys), keys, backward) < 0
+		})
+
+		if position == nil {
+			if page.Offset >= len(offers) {
+				return nil, nil
+			}
+
+			offers = offers[page.Offset:]
+		}
+
+		if len(offers) > limit {
+			offers = offers[:limit]
+		}
+
+		return offers, nil
+	}
+
+	return paginate(s.cursorKey, page, count, fetch)
+}
+
+func (s *memoryRepository) ListDeleted(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error) {
+	_, end := instrument(ctx, "ListDeleted")
+	defer end()
+
+	return s.list(page, filter, true)
+}
+
+// Update bumps the version like updateOffer does, unless there are no
+// changes.
+func (s *memoryRepository) Update(ctx context.Context, offerID string, changes *Changes, check Check) (*Offer, error) {
+	_, end := instrument(ctx, "Update")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offer, err := s.lock(offerID, false, check)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(changes.columns()) > 0 {
+		changes.apply(offer)
+		offer.Version++
+		offer.UpdatedAt = time.Now()
+	}
+
+	updated := *offer
+
+	return &updated, nil
+}
+
+// lock finds the offer like find does and runs check on a copy of it, so
+// that check can't change it. The caller holds the write lock.
+func (s *memoryRepository) lock(offerID string, deleted bool, check Check) (*Offer, error) {
+	offer, err := s.find(offerID, deleted)
+	if err != nil {
+		return nil, err
+	}
+
+	checked := *offer
+	if err := check(&checked); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+func (s *memoryRepository) Delete(ctx context.Context, offerID string, check Check) error {
+	_, end := instrument(ctx, "DeleteByID")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offer, err := s.lock(offerID, false, check)
+	if err != nil {
+		return err
+	}
+
+	offer.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	return nil
+}
+
+// LockExpiry never reports ErrLocked, there is no one to share the offers
+// with.
+func (s *memoryRepository) LockExpiry(ctx context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+func (s *memoryRepository) Expire(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	_, end := instrument(ctx, "Expire")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var swept int64
+
+	for _, o := range s.offers {
+		if swept == int64(batchSize) {
+			break
+		}
+
+		if o.DeletedAt.Valid || !o.ExpirationDate.Valid || o.ExpirationDate.Time.After(before) {
+			continue
+		}
+
+		o.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		swept++
+	}
+
+	return swept, nil
+}
+
+// Each works on a copy of the matching offers, taken up front, so that fn
+// runs without holding the lock.
+func (s *memoryRepository) Each(ctx context.Context, filter *api.OffersFilter, fn func(*Offer) error) error {
+	_, end := instrument(ctx, "Export")
+	defer end()
+
+	s.mu.RLock()
+
+	now := time.Now().UTC()
+
+	var offers []Offer
+	for _, o := range s.offers {
+		if !o.DeletedAt.Valid && matchesFilter(o, filter, now) {
+			offers = append(offers, *o)
+		}
+	}
+
+	s.mu.RUnlock()
+
+	for i := range offers {
+		if err := fn(&offers[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *memoryRepository) CreateBatch(ctx context.Context, offers []*Offer, bestEffort bool) ([]error, error) {
+	_, end := instrument(ctx, "CreateBatch")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(offers))
+	stored := len(s.offers)
+
+	for i, offer := range offers {
+		if offer != nil {
+			errs[i] = s.insert(offer)
+		}
+	}
+
+	if err := batchOutcome(errs, bestEffort); err != nil {
+		for _, offer := range s.offers[stored:] {
+			delete(s.byUUID, offer.UUID)
+		}
+
+		s.offers = s.offers[:stored]
+
+		return errs, err
+	}
+
+	return errs, nil
+}
+
+func (s *memoryRepository) UpdateBatch(ctx context.Context, offerIDs []string, changes []*Changes, checks []Check, bestEffort bool) ([]*Offer, []error, error) {
+	_, end := instrument(ctx, "UpdateBatch")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offers := make([]*Offer, len(offerIDs))
+	errs := make([]error, len(offerIDs))
+
+	// originals keeps the offers as they were before the batch, to roll
+	// back to.
+	originals := make(map[*Offer]Offer)
+
+	for i, offerID := range offerIDs {
+		if changes[i] == nil {
+			continue
+		}
+
+		offer, err := s.lock(offerID, false, checks[i])
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		if _, ok := originals[offer]; !ok {
+			originals[offer] = *offer
+		}
+
+		if len(changes[i].columns()) > 0 {
+			changes[i].apply(offer)
+			offer.Version++
+			offer.UpdatedAt = time.Now()
+		}
+
+		updated := *offer
+		offers[i] = &updated
+	}
+
+	if err := batchOutcome(errs, bestEffort); err != nil {
+		for offer, original := range originals {
+			*offer = original
+		}
+
+		return nil, errs, err
+	}
+
+	return offers, errs, nil
+}
+
+func (s *memoryRepository) DeleteBatch(ctx context.Context, offerIDs []string, checks []Check, bestEffort bool) ([]error, error) {
+	_, end := instrument(ctx, "DeleteBatch")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(offerIDs))
+
+	var deleted []*Offer
+
+	for i, offerID := range offerIDs {
+		offer, err := s.lock(offerID, false, checks[i])
+		if err != nil {
+			errs[i] = err
+
+			continue
+		}
+
+		offer.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		deleted = append(deleted, offer)
+	}
+
+	if err := batchOutcome(errs, bestEffort); err != nil {
+		for _, offer := range deleted {
+			offer.DeletedAt = gorm.DeletedAt{}
+		}
+
+		return errs, err
+	}
+
+	return errs, nil
+}
+
+// matchesFilter mirrors filterOffers.
+func matchesFilter(o *Offer, f *api.OffersFilter, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Company != "" && strings.ToLower(o.Company) != strings.ToLower(f.Company) {
+		return false
+	}
+
+	if f.Owner != "" && (!o.OwnerID.Valid || o.OwnerID.String != f.Owner) {
+		return false
+	}
+
+	if f.MinSalary != nil && o.Salary < *f.MinSalary {
+		return false
+	}
+
+	if f.MaxSalary != nil && o.Salary > *f.MaxSalary {
+		return false
+	}
+
+	if f.ExpiresAfter != nil && (!o.ExpirationDate.Valid || o.ExpirationDate.Time.Before(*f.ExpiresAfter)) {
+		return false
+	}
+
+	if f.ExpiresBefore != nil && (!o.ExpirationDate.Valid || o.ExpirationDate.Time.After(*f.ExpiresBefore)) {
+		return false
+	}
+
+	if f.Query != "" && !matchesQuery(o.Company+" "+o.Details.ValueOrZero(), f.Query) {
+		return false
+	}
+
+	if !f.IncludeExpired && o.ExpirationDate.Valid && !o.ExpirationDate.Time.After(now) {
+		return false
+	}
+
+	return true
+}
+
+// matchesQuery tells whether the document holds every word of the query,
+// ignoring case, like plainto_tsquery does short of stemming.
+func matchesQuery(document, query string) bool {
+	words := make(map[string]bool)
+	for _, w := range splitWords(document) {
+		words[w] = true
+	}
+
+	for _, w := range splitWords(query) {
+		if !words[w] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// sortValues are the values of the offer for keys, as cursorAt records
+// them.
+func sortValues(o Offer, keys []api.SortKey) []interface{} {
+	values := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, sortFields[k.Field].value(o))
+	}
+
+	return values
+}
+
+// compareKeys orders two rows by their sort key values the way seekOffers
+// has the database order them, reversed when paging backwards.
+func compareKeys(a, b []interface{}, keys []api.SortKey, backward bool) int {
+	for i, k := range keys {
+		c := compareValues(sortable(k.Field, a[i]), sortable(k.Field, b[i]))
+
+		if k.Descending != backward {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// sortable turns a sort key value, taken from an offer or decoded from a
+// cursor, into a float64, string or time.Time.
+func sortable(field string, v interface{}) interface{} {
+	switch v := cursorValue(field, v).(type) {
+	case uint:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case string:
+		if v == farFuture && sortFields[field].timestamp {
+			t, _ := time.Parse("2006-01-02 15:04:05", farFuture)
+
+			return t
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case float64:
+		b, _ := b.(float64)
+
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		b, _ := b.(string)
+
+		return strings.Compare(a, b)
+	case time.Time:
+		b, _ := b.(time.Time)
+
+		switch {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func (s *memoryRepository) Restore(ctx context.Context, offerID string, check Check) (*Offer, error) {
+	_, end := instrument(ctx, "Restore")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offer, err := s.lock(offerID, true, check)
+	if err != nil {
+		return nil, err
+	}
+
+	offer.DeletedAt = gorm.DeletedAt{}
+	offer.Version++
+	offer.UpdatedAt = time.Now()
+
+	restored := *offer
+
+	return &restored, nil
+}
+
+func (s *memoryRepository) Purge(ctx context.Context, offerID string, check Check) error {
+	_, end := instrument(ctx, "Purge")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offer, err := s.lookup(offerID)
+	if err != nil {
+		return err
+	}
+
+	checked := *offer
+	if err := check(&checked); err != nil {
+		return err
+	}
+
+	s.remove(func(o *Offer) bool { return o == offer })
+
+	return nil
+}
+
+func (s *memoryRepository) PurgeDeleted(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	_, end := instrument(ctx, "PurgeDeleted")
+	defer end()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+
+	s.remove(func(o *Offer) bool {
+		if purged == int64(batchSize) || !o.DeletedAt.Valid || o.DeletedAt.Time.After(before) {
+			return false
+		}
+
+		purged++
+
+		return true
+	})
+
+	return purged, nil
+}
+
+// remove drops the offers for which drop is true, visiting them in id
+// order. The caller holds the write lock.
+func (s *memoryRepository) remove(drop func(*Offer) bool) {
+	kept := s.offers[:0]
+
+	for _, o := range s.offers {
+		if drop(o) {
+			delete(s.byUUID, o.UUID)
+
+			continue
+		}
+
+		kept = append(kept, o)
+	}
+
+	s.offers = kept
+}
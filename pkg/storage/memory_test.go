@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"example.com/playground/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryConcurrentWrites(t *testing.T) {
+	r := NewMemoryRepository(testCursorKey)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			offer := &Offer{Company: "ACME", Version: 1}
+			assert.Nil(t, r.Create(context.Background(), offer))
+
+			salary := 1.0
+
+			_, err := r.Update(context.Background(), offer.UUID.String(), &Changes{Salary: &salary}, allow)
+			assert.Nil(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	page, err := r.List(context.Background(), &api.PageRequest{Size: 100}, &api.OffersFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(50), *page.TotalCount)
+
+	for _, o := range page.Offers {
+		assert.Equal(t, int64(2), o.Version)
+	}
+}
+
+func TestMatchesQuery(t *testing.T) {
+	tests := []struct {
+		document string
+		query    string
+		expected bool
+	}{
+		{"ACME Golang, SQL", "golang", true},
+		{"ACME Golang, SQL", "sql GOLANG", true},
+		{"ACME Golang, SQL", "go", false},
+		{"ACME Golang, SQL", "golang java", false},
+		{"ACME", "", true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, matchesQuery(test.document, test.query), test)
+	}
+}
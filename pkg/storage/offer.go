@@ -2,199 +2,334 @@ package storage
 
 import (
 	"context"
+	"database/sql/driver"
+	"strings"
+	"time"
 
 	"example.com/playground/pkg/api"
 
 	"github.com/gofrs/uuid"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type jobOffer struct {
+type Offer struct {
 	gorm.Model
 
 	UUID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"uuid"`
 
 	Company        string      `json:"company"`
 	Email          string      `json:"email"`
-	ExpirationDate null.String `json:"expiration_date"`
+	ExpirationDate null.Time   `json:"expiration_date"`
 	LinkToOffer    null.String `json:"link"`
 	Details        null.String `json:"details"`
 	Phone          string      `json:"phone"`
 	Salary         float64     `json:"salary"`
+	OwnerID        null.String `json:"owner_id"`
+	Version        int64       `json:"version"`
 }
 
-func (j *jobOffer) TableName() string {
+func (o *Offer) TableName() string {
 	return "job_offers"
 }
 
-type CreateOffer interface {
-	Create(context.Context, *api.JobOfferRequest) (*api.JobOfferResponse, error)
-}
+// BeforeCreate gives new offers their uuid. It's generated here rather than
+// by the database, SQLite has no uuid_generate_v4().
+func (o *Offer) BeforeCreate(*gorm.DB) error {
+	if o.UUID != uuid.Nil {
+		return nil
+	}
 
-type GetOffer interface {
-	Get(context.Context, string) (*api.JobOfferResponse, error)
-}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
 
-type GetAllOffers interface {
-	GetAll(context.Context, int, int, string) (*api.JobOffersPaginationResponse, error)
-}
+	o.UUID = id
 
-type UpdateOffer interface {
-	Update(context.Context, string, *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error)
+	return nil
 }
 
-type DeleteOffer interface {
-	DeleteByID(context.Context, string) error
+// dbRepository keeps offers in PostgreSQL or SQLite.
+type dbRepository struct {
+	db        *gorm.DB
+	cursorKey []byte
 }
 
-type dbService struct {
-	db *gorm.DB
+// searchDocument must stay in sync with the job_offers_search_idx expression
+// in the migrations, otherwise full-text queries can't use the GIN index.
+const searchDocument = "to_tsvector('english', coalesce(company, '') || ' ' || coalesce(details, ''))"
+
+// isSQLite tells whether tx talks to SQLite rather than PostgreSQL.
+func isSQLite(tx *gorm.DB) bool {
+	return tx.Dialector.Name() == "sqlite"
 }
 
-func (s *dbService) GetAll(ctx context.Context, size, offset int, sortBy string) (*api.JobOffersPaginationResponse, error) {
-	var totalCount int64
-	var offer jobOffer
+func filterOffers(f *api.OffersFilter) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if f == nil {
+			return tx
+		}
 
-	if err := s.db.WithContext(ctx).
-		Model(&offer).
-		Count(&totalCount).
-		Error; err != nil {
+		if f.Company != "" {
+			tx = tx.Where("lower(company) = lower(?)", f.Company)
+		}
 
-		return nil, err
-	}
+		if f.Owner != "" {
+			tx = tx.Where("owner_id = ?", f.Owner)
+		}
 
-	var offers []jobOffer
+		if f.MinSalary != nil {
+			tx = tx.Where("salary >= ?", *f.MinSalary)
+		}
 
-	if err := s.db.WithContext(ctx).
-		Offset(offset).
-		Limit(size).
-		Order(sortBy).
-		Find(&offers).
-		Error; err != nil {
+		if f.MaxSalary != nil {
+			tx = tx.Where("salary <= ?", *f.MaxSalary)
+		}
 
-		return nil, err
-	}
+		if f.ExpiresAfter != nil {
+			tx = tx.Where("expiration_date >= ?", f.ExpiresAfter.UTC())
+		}
+
+		if f.ExpiresBefore != nil {
+			tx = tx.Where("expiration_date <= ?", f.ExpiresBefore.UTC())
+		}
 
-	data := make([]api.JobOfferResponse, 0)
-
-	for _, o := range offers {
-		if o.ID > 0 {
-			jobOfferResponse := api.JobOfferResponse{
-				ID:             o.UUID.String(),
-				Company:        o.Company,
-				Email:          o.Email,
-				ExpirationDate: o.ExpirationDate.ValueOrZero(),
-				LinkToOffer:    o.LinkToOffer.ValueOrZero(),
-				Details:        o.Details.ValueOrZero(),
-				Salary:         o.Salary,
-				ContactPhone:   o.Phone,
+		if f.Query != "" && isSQLite(tx) {
+			// On SQLite every word of the query has to be a word of company
+			// or details, as the job_offers_search index splits them.
+			if words := splitWords(f.Query); len(words) > 0 {
+				tx = tx.Where("id IN (SELECT docid FROM job_offers_search WHERE job_offers_search MATCH ?)", `"`+strings.Join(words, `" "`)+`"`)
 			}
-			data = append(data, jobOfferResponse)
+		} else if f.Query != "" {
+			tx = tx.Where(searchDocument+" @@ plainto_tsquery('english', ?)", f.Query)
 		}
+
+		if !f.IncludeExpired {
+			tx = tx.Where("(expiration_date IS NULL OR expiration_date > ?)", time.Now().UTC())
+		}
+
+		return tx
 	}
+}
 
-	return &api.JobOffersPaginationResponse{
-		TotalCount: totalCount,
-		Data:       data,
-	}, nil
+func (s *dbRepository) List(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error) {
+	ctx, end := instrument(ctx, "GetAll")
+	defer end()
+
+	return s.list(ctx, page, filter, func(tx *gorm.DB) *gorm.DB { return tx })
 }
 
-func (s *dbService) Create(ctx context.Context, req *api.JobOfferRequest) (*api.JobOfferResponse, error) {
-	offer := &jobOffer{
-		Company:        req.Company,
-		Email:          req.Email,
-		ExpirationDate: null.StringFrom(req.ExpirationDate),
-		LinkToOffer:    null.StringFrom(req.LinkToOffer),
-		Details:        null.StringFrom(req.Details),
-		Salary:         req.Salary,
-		Phone:          req.ContactPhone,
+// list pages through the offers that scope picks.
+func (s *dbRepository) list(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter, scope func(*gorm.DB) *gorm.DB) (*OfferPage, error) {
+	count := func() (int64, error) {
+		var count int64
+
+		err := s.db.WithContext(ctx).
+			Model(&Offer{}).
+			Scopes(scope, filterOffers(filter)).
+			Count(&count).
+			Error
+
+		return count, err
+	}
+
+	fetch := func(position *cursor, limit int) ([]Offer, error) {
+		var offers []Offer
+
+		err := s.db.WithContext(ctx).
+			Scopes(scope, filterOffers(filter), seekOffers(page, position)).
+			Limit(limit).
+			Find(&offers).
+			Error
+
+		return offers, err
 	}
 
+	return paginate(s.cursorKey, page, count, fetch)
+}
+
+func (s *dbRepository) Create(ctx context.Context, offer *Offer) error {
+	ctx, end := instrument(ctx, "Create")
+	defer end()
+
 	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		return tx.Create(offer).Error
 	})
-	if err != nil {
-		return nil, err
-	}
 
-	return &api.JobOfferResponse{
-		ID:             offer.UUID.String(),
-		Company:        offer.Company,
-		Email:          offer.Email,
-		ExpirationDate: offer.ExpirationDate.ValueOrZero(),
-		LinkToOffer:    offer.LinkToOffer.ValueOrZero(),
-		Details:        offer.Details.ValueOrZero(),
-		Salary:         offer.Salary,
-		ContactPhone:   offer.Phone,
-	}, nil
+	return translateError(err)
 }
 
-func (s *dbService) Get(ctx context.Context, offerID string) (*api.JobOfferResponse, error) {
-	var offer jobOffer
+func (s *dbRepository) Get(ctx context.Context, offerID string) (*Offer, error) {
+	ctx, end := instrument(ctx, "Get")
+	defer end()
+
+	var offer Offer
 
 	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		return tx.Where("uuid = (?)", offerID).
-			Find(&offer).
+			First(&offer).
 			Error
 	}); err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	return &api.JobOfferResponse{
-		ID:             offer.UUID.String(),
-		Company:        offer.Company,
-		Email:          offer.Email,
-		ExpirationDate: offer.ExpirationDate.ValueOrZero(),
-		LinkToOffer:    offer.LinkToOffer.ValueOrZero(),
-		Details:        offer.Details.ValueOrZero(),
-		Salary:         offer.Salary,
-		ContactPhone:   offer.Phone,
-	}, nil
+	return &offer, nil
 }
 
-func (s *dbService) Update(ctx context.Context, offerID string, req *api.UpdateJobOfferRequest) (*api.JobOfferResponse, error) {
-	var offer jobOffer
+// Update writes the changes in a single UPDATE.
+func (s *dbRepository) Update(ctx context.Context, offerID string, changes *Changes, check Check) (*Offer, error) {
+	ctx, end := instrument(ctx, "Update")
+	defer end()
+
+	columns := changes.columns()
+
+	var offer Offer
 
 	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return tx.Where("uuid = (?)", offerID).
-			Find(&offer).
-			UpdateColumn("salary", req.Salary).
-			UpdateColumn("email", req.Email).
-			UpdateColumn("phone", req.ContactPhone).
-			UpdateColumn("link_to_offer", req.LinkToOffer).
-			Error
+		if err := lockOffer(tx, offerID, check, &offer); err != nil {
+			return err
+		}
+
+		if len(columns) == 0 {
+			return nil
+		}
+
+		return updateOffer(tx, &offer, columns)
 	}); err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
-	return &api.JobOfferResponse{
-		ID:             offer.UUID.String(),
-		Company:        offer.Company,
-		Email:          offer.Email,
-		ExpirationDate: offer.ExpirationDate.ValueOrZero(),
-		LinkToOffer:    offer.LinkToOffer.ValueOrZero(),
-		Details:        offer.Details.ValueOrZero(),
-		Salary:         offer.Salary,
-		ContactPhone:   offer.Phone,
-	}, nil
+	return &offer, nil
 }
 
-func (s *dbService) DeleteByID(ctx context.Context, offerID string) error {
+func (s *dbRepository) Delete(ctx context.Context, offerID string, check Check) error {
+	ctx, end := instrument(ctx, "DeleteByID")
+	defer end()
 
-	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return tx.Where("uuid = (?)", offerID).
-			Delete(&jobOffer{}).
-			Error
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var offer Offer
+		if err := lockOffer(tx, offerID, check, &offer); err != nil {
+			return err
+		}
+
+		res := tx.Delete(&offer)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		return nil
 	})
+
+	return translateError(err)
 }
 
-func NewCreateOfferService(db *gorm.DB) CreateOffer { return &dbService{db: db} }
+// updateOffer writes changes to a locked offer in one UPDATE, which also
+// bumps its version and updated_at, and reloads the offer so that callers
+// see what was actually stored.
+func updateOffer(tx *gorm.DB, offer *Offer, changes map[string]interface{}) error {
+	changes["version"] = gorm.Expr("version + 1")
 
-func NewUpdateOfferService(db *gorm.DB) UpdateOffer { return &dbService{db: db} }
+	if err := tx.Model(offer).Updates(changes).Error; err != nil {
+		return err
+	}
 
-func NewGetOfferService(db *gorm.DB) GetOffer { return &dbService{db: db} }
+	return tx.First(offer, offer.ID).Error
+}
 
-func NewDeleteOfferService(db *gorm.DB) DeleteOffer { return &dbService{db: db} }
+// lockOffer loads the offer for update and runs check on it.
+func lockOffer(tx *gorm.DB, offerID string, check Check, offer *Offer) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uuid = (?)", offerID).
+		First(offer).
+		Error; err != nil {
 
-func NewGetAllOffersService(db *gorm.DB) GetAllOffers { return &dbService{db: db} }
+		return err
+	}
+
+	return check(offer)
+}
+
+// expiryLockID identifies the advisory lock that keeps replicas from
+// sweeping expired offers concurrently.
+const expiryLockID = 7_304_100_451
+
+// LockExpiry takes a session-level advisory lock on a connection of its
+// own, held until the sweep releases it, so that the batches of a sweep
+// don't interleave with another replica's. SQLite lets only one transaction
+// write at a time anyway, so there is no lock to take there.
+func (s *dbRepository) LockExpiry(ctx context.Context) (func(), error) {
+	ctx, end := instrument(ctx, "LockExpiry")
+	defer end()
+
+	if isSQLite(s.db) {
+		return func() {}, nil
+	}
+
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", expiryLockID).Scan(&locked); err != nil {
+		conn.Close()
+
+		return nil, err
+	}
+
+	if !locked {
+		conn.Close()
+
+		return nil, ErrLocked
+	}
+
+	return func() {
+		// Unlock even when ctx is done. Should that fail, the connection is
+		// dropped rather than going back to the pool still holding the lock.
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", expiryLockID); err != nil {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+
+		conn.Close()
+	}, nil
+}
+
+func (s *dbRepository) Expire(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	ctx, end := instrument(ctx, "Expire")
+	defer end()
+
+	var swept int64
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&Offer{}).
+			Select("id").
+			Where("expiration_date <= ?", before.UTC()).
+			Order("id").
+			Limit(batchSize)
+
+		res := tx.Where("id IN (?)", expired).Delete(&Offer{})
+		swept = res.RowsAffected
+
+		return res.Error
+	})
+
+	return swept, err
+}
+
+// NewRepository returns the repository keeping offers in db, which may be
+// PostgreSQL or SQLite. cursorKey signs the cursors of listings.
+func NewRepository(db *gorm.DB, cursorKey []byte) OfferRepository {
+	return &dbRepository{db: db, cursorKey: cursorKey}
+}
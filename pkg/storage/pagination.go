@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"example.com/playground/pkg/api"
+
+	"gorm.io/gorm"
+)
+
+type sortField struct {
+	column    string
+	value     func(Offer) interface{}
+	timestamp bool
+}
+
+// farFuture stands in for offers without an expiration date so that they
+// sort after every dated offer and keyset comparisons never meet a NULL.
+const farFuture = "9999-12-31 23:59:59"
+
+// sortFields whitelists the API field names clients may sort by and maps
+// them to the column expressions used in ORDER BY and keyset conditions.
+var sortFields = map[string]sortField{
+	"id": {
+		column: "id",
+		value:  func(o Offer) interface{} { return o.ID },
+	},
+	"uuid": {
+		column: "uuid",
+		value:  func(o Offer) interface{} { return o.UUID.String() },
+	},
+	"company": {
+		column: "company",
+		value:  func(o Offer) interface{} { return o.Company },
+	},
+	"email": {
+		column: "email",
+		value:  func(o Offer) interface{} { return o.Email },
+	},
+	"phone": {
+		column: "phone",
+		value:  func(o Offer) interface{} { return o.Phone },
+	},
+	"salary": {
+		column: "salary",
+		value:  func(o Offer) interface{} { return o.Salary },
+	},
+	"details": {
+		column: "coalesce(details, '')",
+		value:  func(o Offer) interface{} { return o.Details.ValueOrZero() },
+	},
+	"link": {
+		column: "coalesce(link_to_offer, '')",
+		value:  func(o Offer) interface{} { return o.LinkToOffer.ValueOrZero() },
+	},
+	"expiration_date": {
+		column: "coalesce(expiration_date, '" + farFuture + "')",
+		value: func(o Offer) interface{} {
+			if !o.ExpirationDate.Valid {
+				return farFuture
+			}
+
+			return o.ExpirationDate.Time
+		},
+		timestamp: true,
+	},
+	"created_at": {
+		column:    "created_at",
+		value:     func(o Offer) interface{} { return o.CreatedAt },
+		timestamp: true,
+	},
+	"updated_at": {
+		column:    "updated_at",
+		value:     func(o Offer) interface{} { return o.UpdatedAt },
+		timestamp: true,
+	},
+}
+
+func IsSortable(field string) bool {
+	_, ok := sortFields[field]
+
+	return ok
+}
+
+// sortKeys appends id as a tiebreaker so that the ordering is total, which
+// keyset pagination relies on.
+func sortKeys(sort []api.SortKey) []api.SortKey {
+	for _, k := range sort {
+		if k.Field == "id" {
+			return sort
+		}
+	}
+
+	return append(append([]api.SortKey{}, sort...), api.SortKey{Field: "id"})
+}
+
+func sortSpec(sort []api.SortKey) string {
+	spec := make([]string, 0, len(sort))
+	for _, k := range sort {
+		spec = append(spec, k.String())
+	}
+
+	return strings.Join(spec, ",")
+}
+
+// seekOffers orders the listing by the requested keys and positions it
+// either by offset or, when a cursor is given, by keyset so pages stay
+// stable while rows are being inserted.
+func seekOffers(page *api.PageRequest, position *cursor) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		keys := sortKeys(page.Sort)
+		backward := position != nil && position.Backward
+
+		for _, k := range keys {
+			if k.Descending != backward {
+				tx = tx.Order(sortFields[k.Field].column + " DESC")
+			} else {
+				tx = tx.Order(sortFields[k.Field].column + " ASC")
+			}
+		}
+
+		if position == nil {
+			return tx.Offset(page.Offset)
+		}
+
+		if len(position.Values) != len(keys) {
+			_ = tx.AddError(ErrInvalidCursor)
+
+			return tx
+		}
+
+		// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with the comparison
+		// flipped for descending keys and when paging backwards.
+		var conditions []string
+		var args []interface{}
+
+		for i, k := range keys {
+			var terms []string
+
+			for j := 0; j < i; j++ {
+				terms = append(terms, sortFields[keys[j].Field].column+" = ?")
+				args = append(args, cursorValue(keys[j].Field, position.Values[j]))
+			}
+
+			op := " > ?"
+			if k.Descending != backward {
+				op = " < ?"
+			}
+
+			terms = append(terms, sortFields[k.Field].column+op)
+			args = append(args, cursorValue(k.Field, position.Values[i]))
+
+			conditions = append(conditions, "("+strings.Join(terms, " AND ")+")")
+		}
+
+		return tx.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// cursorValue turns a sort key value decoded from a cursor back into the
+// type of its column. PostgreSQL parses timestamps sent as text, but SQLite
+// would compare them to the stored text as they are.
+func cursorValue(field string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+
+		f, _ := v.Float64()
+
+		return f
+	case string:
+		if !sortFields[field].timestamp || v == farFuture {
+			return v
+		}
+
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC()
+		}
+	}
+
+	return v
+}
+
+func cursorAt(o Offer, sort []api.SortKey, backward bool) cursor {
+	keys := sortKeys(sort)
+
+	values := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, sortFields[k.Field].value(o))
+	}
+
+	return cursor{
+		Sort:     sortSpec(sort),
+		Values:   values,
+		Backward: backward,
+	}
+}
+
+// paginate assembles a page of a listing. count tells how many offers match
+// the listing as a whole and fetch reads up to limit of them in the order
+// and from the position seekOffers describes. Both backends page through
+// it, so that cursors and page boundaries behave the same on either.
+func paginate(
+	key []byte,
+	page *api.PageRequest,
+	count func() (int64, error),
+	fetch func(position *cursor, limit int) ([]Offer, error),
+) (*OfferPage, error) {
+	var position *cursor
+
+	if page.Cursor != "" {
+		c, err := decodeCursor(key, page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Sort != sortSpec(page.Sort) {
+			return nil, ErrInvalidCursor
+		}
+
+		position = c
+	}
+
+	var totalCount *int64
+
+	// Counting is what makes deep offset pagination slow, so it's only done
+	// in offset mode where clients rely on it to render page numbers.
+	if position == nil {
+		n, err := count()
+		if err != nil {
+			return nil, err
+		}
+
+		totalCount = &n
+	}
+
+	offers, err := fetch(position, page.Size+1)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := position != nil && position.Backward
+
+	hasMore := len(offers) > page.Size
+	if hasMore {
+		offers = offers[:page.Size]
+	}
+
+	if backward {
+		for i, j := 0, len(offers)-1; i < j; i, j = i+1, j-1 {
+			offers[i], offers[j] = offers[j], offers[i]
+		}
+	}
+
+	resp := &OfferPage{
+		Offers:     offers,
+		TotalCount: totalCount,
+	}
+
+	if len(offers) == 0 {
+		return resp, nil
+	}
+
+	// Paging backward there is always a next page, the one we came from.
+	// Paging forward there is a previous one unless we started at the top.
+	started := position != nil || page.Offset > 0
+
+	if hasMore || backward {
+		next, err := encodeCursor(key, cursorAt(offers[len(offers)-1], page.Sort, false))
+		if err != nil {
+			return nil, err
+		}
+
+		resp.NextCursor = next
+	}
+
+	if (backward && hasMore) || (!backward && started) {
+		prev, err := encodeCursor(key, cursorAt(offers[0], page.Sort, true))
+		if err != nil {
+			return nil, err
+		}
+
+		resp.PrevCursor = prev
+	}
+
+	return resp, nil
+}
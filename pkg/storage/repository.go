@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"example.com/playground/pkg/api"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// OfferRepository keeps offers. Every backend implements it, and they all
+// behave the same, which the conformance tests make sure of. Offers are
+// identified by their uuid. Deleting an offer moves it to the trash, where
+// only ListDeleted, Restore and the purges see it.
+type OfferRepository interface {
+	// Create stores a new offer, filling in its ids and timestamps.
+	Create(ctx context.Context, offer *Offer) error
+	Get(ctx context.Context, offerID string) (*Offer, error)
+	List(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error)
+
+	// Update applies changes to an offer, bumping its version, once check
+	// has approved the offer as it is stored. Empty changes leave the offer
+	// alone. The offer is returned as stored afterwards.
+	Update(ctx context.Context, offerID string, changes *Changes, check Check) (*Offer, error)
+	Delete(ctx context.Context, offerID string, check Check) error
+
+	// Each hands every offer matching the filter to fn, in the order they
+	// were created, without holding them all in memory at once. Returning
+	// an error from fn stops it.
+	Each(ctx context.Context, filter *api.OffersFilter, fn func(*Offer) error) error
+
+	// LockExpiry makes sure a single runner sweeps expired offers at a
+	// time. It returns ErrLocked while someone else holds the lock, and
+	// otherwise a function that releases it once the sweep is done.
+	LockExpiry(ctx context.Context) (func(), error)
+
+	// Expire deletes up to batchSize offers that expired before the given
+	// time and reports how many there were. It takes no lock itself, a
+	// sweep running it batch by batch holds LockExpiry throughout.
+	Expire(ctx context.Context, before time.Time, batchSize int) (int64, error)
+
+	// The batches run in one transaction and return an error per item, in
+	// order, skipping nil offers and nil changes. Unless bestEffort is set,
+	// they fail with ErrBatchFailed as soon as one item does.
+	CreateBatch(ctx context.Context, offers []*Offer, bestEffort bool) ([]error, error)
+
+	// UpdateBatch applies changes[i] to offerIDs[i] like Update does, vetted
+	// by checks[i], and returns the offers as stored afterwards.
+	UpdateBatch(ctx context.Context, offerIDs []string, changes []*Changes, checks []Check, bestEffort bool) ([]*Offer, []error, error)
+	DeleteBatch(ctx context.Context, offerIDs []string, checks []Check, bestEffort bool) ([]error, error)
+
+	// ListDeleted lists the offers in the trash like List lists the others.
+	ListDeleted(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error)
+
+	// Restore takes an offer out of the trash, bumping its version, once
+	// check has approved it. Offers that aren't in the trash aren't found.
+	Restore(ctx context.Context, offerID string, check Check) (*Offer, error)
+
+	// Purge deletes an offer for good, whether it's in the trash or not.
+	Purge(ctx context.Context, offerID string, check Check) error
+
+	// PurgeDeleted deletes up to batchSize offers for good that were moved
+	// to the trash before the given time and reports how many there were.
+	PurgeDeleted(ctx context.Context, before time.Time, batchSize int) (int64, error)
+}
+
+// Check vets an offer before it's changed or deleted. It sees the offer as
+// stored, locked against concurrent changes until the change is done.
+type Check func(*Offer) error
+
+// OfferPage is a page of a listing. TotalCount is only known for pages
+// requested by offset.
+type OfferPage struct {
+	Offers     []Offer
+	TotalCount *int64
+	NextCursor string
+	PrevCursor string
+}
+
+// Changes lists the fields of an offer to change, the ones left nil stay as
+// they are.
+type Changes struct {
+	Company        *string
+	Email          *string
+	Phone          *string
+	Salary         *float64
+	LinkToOffer    *null.String
+	Details        *null.String
+	ExpirationDate *null.Time
+}
+
+// columns maps the changes to the columns they write.
+func (c *Changes) columns() map[string]interface{} {
+	columns := make(map[string]interface{})
+
+	if c.Company != nil {
+		columns["company"] = *c.Company
+	}
+
+	if c.Email != nil {
+		columns["email"] = *c.Email
+	}
+
+	if c.Phone != nil {
+		columns["phone"] = *c.Phone
+	}
+
+	if c.Salary != nil {
+		columns["salary"] = *c.Salary
+	}
+
+	if c.LinkToOffer != nil {
+		columns["link_to_offer"] = *c.LinkToOffer
+	}
+
+	if c.Details != nil {
+		columns["details"] = *c.Details
+	}
+
+	if c.ExpirationDate != nil {
+		columns["expiration_date"] = *c.ExpirationDate
+	}
+
+	return columns
+}
+
+// apply makes the changes to o.
+func (c *Changes) apply(o *Offer) {
+	if c.Company != nil {
+		o.Company = *c.Company
+	}
+
+	if c.Email != nil {
+		o.Email = *c.Email
+	}
+
+	if c.Phone != nil {
+		o.Phone = *c.Phone
+	}
+
+	if c.Salary != nil {
+		o.Salary = *c.Salary
+	}
+
+	if c.LinkToOffer != nil {
+		o.LinkToOffer = *c.LinkToOffer
+	}
+
+	if c.Details != nil {
+		o.Details = *c.Details
+	}
+
+	if c.ExpirationDate != nil {
+		o.ExpirationDate = *c.ExpirationDate
+	}
+}
@@ -2,10 +2,12 @@ package storage
 
 import (
 	"fmt"
+	"time"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -34,13 +36,51 @@ func (p *PostgresConfig) Dialector() gorm.Dialector {
 	)
 }
 
+// SQLiteConfig points at an SQLite database file, which is created when it
+// doesn't exist yet.
+type SQLiteConfig struct {
+	Path string
+}
+
+// Dialector waits for locks held by other connections instead of failing
+// right away, and has transactions take the write lock when they begin, so
+// that two of them reading and then writing the same offer can't deadlock.
+func (s *SQLiteConfig) Dialector() gorm.Dialector {
+	return sqlite.Open(fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate", s.Path))
+}
+
+// SetupDatabase opens a PostgreSQL database. Its timestamp columns have no
+// time zone, so timestamps are written in UTC like the cutoffs they are
+// compared against, whatever the zone of the host.
 func SetupDatabase(p *PostgresConfig, lg *zap.SugaredLogger) (*gorm.DB, error) {
-	return gorm.Open(p.Dialector(), &gorm.Config{
+	return openDatabase(p.Dialector(), utcNow, lg)
+}
+
+// SetupSQLite opens an SQLite database. Timestamps are kept in UTC there,
+// SQLite compares them as text.
+func SetupSQLite(s *SQLiteConfig, lg *zap.SugaredLogger) (*gorm.DB, error) {
+	return openDatabase(s.Dialector(), utcNow, lg)
+}
+
+func utcNow() time.Time { return time.Now().UTC() }
+
+func openDatabase(dialector gorm.Dialector, now func() time.Time, lg *zap.SugaredLogger) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.New(
 			zap.NewStdLog(lg.Desugar()),
 			logger.Config{
 				LogLevel: logger.Info,
 			},
 		),
+		NowFunc: now,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Use(tracingPlugin{}); err != nil {
+		return nil, err
+	}
+
+	return db, nil
 }
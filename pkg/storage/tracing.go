@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var tracer = otel.Tracer("example.com/playground/pkg/storage")
+
+const spanKey = "otel:span"
+
+// tracingPlugin wraps every statement GORM executes in a client span that
+// carries the SQL, nested under the span of the calling repository method.
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string {
+	return "tracing"
+}
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := tracer.Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBOperationKey.String(operation),
+				semconv.DBSQLTableKey.String(db.Statement.Table),
+			),
+		)
+
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+
+	span := v.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
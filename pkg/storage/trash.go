@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"example.com/playground/pkg/api"
+
+	"gorm.io/gorm"
+)
+
+// deleted picks the offers in the trash, which GORM hides otherwise.
+func deleted(tx *gorm.DB) *gorm.DB {
+	return tx.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+func (s *dbRepository) ListDeleted(ctx context.Context, page *api.PageRequest, filter *api.OffersFilter) (*OfferPage, error) {
+	ctx, end := instrument(ctx, "ListDeleted")
+	defer end()
+
+	return s.list(ctx, page, filter, deleted)
+}
+
+func (s *dbRepository) Restore(ctx context.Context, offerID string, check Check) (*Offer, error) {
+	ctx, end := instrument(ctx, "Restore")
+	defer end()
+
+	var offer Offer
+
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOffer(tx.Scopes(deleted), offerID, check, &offer); err != nil {
+			return err
+		}
+
+		// Like updateOffer, except that the UPDATE has to see past the soft
+		// delete. The offer is out of the trash by the time it's reloaded.
+		if err := tx.Unscoped().
+			Model(&offer).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"version":    gorm.Expr("version + 1"),
+			}).
+			Error; err != nil {
+
+			return err
+		}
+
+		return tx.First(&offer, offer.ID).Error
+	}); err != nil {
+		return nil, translateError(err)
+	}
+
+	return &offer, nil
+}
+
+func (s *dbRepository) Purge(ctx context.Context, offerID string, check Check) error {
+	ctx, end := instrument(ctx, "Purge")
+	defer end()
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var offer Offer
+		if err := lockOffer(tx.Unscoped(), offerID, check, &offer); err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&offer).Error
+	})
+
+	return translateError(err)
+}
+
+// PurgeDeleted needs no lock like Expire does, runners purging at the same
+// time can only delete the same offers twice.
+func (s *dbRepository) PurgeDeleted(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	ctx, end := instrument(ctx, "PurgeDeleted")
+	defer end()
+
+	var purged int64
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		trashed := tx.Unscoped().
+			Model(&Offer{}).
+			Select("id").
+			Where("deleted_at <= ?", before.UTC()).
+			Order("id").
+			Limit(batchSize)
+
+		res := tx.Unscoped().Where("id IN (?)", trashed).Delete(&Offer{})
+		purged = res.RowsAffected
+
+		return res.Error
+	})
+
+	return purged, err
+}
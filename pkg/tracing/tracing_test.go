@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupStdout(t *testing.T) {
+	var out bytes.Buffer
+
+	shutdown, err := Setup(context.Background(), &Config{
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+		ServiceName: "offers-test",
+		Output:      &out,
+	})
+	require.Nil(t, err)
+
+	// An incoming traceparent is continued rather than a new trace started.
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	_, span := otel.Tracer("test").Start(ctx, "GET /offers")
+	sc := span.SpanContext()
+	span.End()
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", trace.SpanContextFromContext(ctx).SpanID().String())
+
+	require.Nil(t, shutdown(context.Background()))
+
+	assert.Contains(t, out.String(), `"Name":"GET /offers"`)
+	assert.Contains(t, out.String(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, out.String(), "00f067aa0ba902b7")
+	assert.Contains(t, out.String(), "offers-test")
+}
+
+func TestSetupExporters(t *testing.T) {
+	shutdown, err := Setup(context.Background(), &Config{Exporter: ExporterNone})
+	assert.Nil(t, err)
+	assert.Nil(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), &Config{Exporter: "zipkin"})
+	assert.NotNil(t, err)
+}